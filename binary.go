@@ -0,0 +1,347 @@
+package qparams
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// binaryVersion is the version of the binary encoding, written as the
+// first byte so that the format can evolve.
+const binaryVersion = 1
+
+// maxBinaryDepth bounds the nesting of the filter groups decoded by
+// UnmarshalBinary.
+const maxBinaryDepth = 64
+
+// flags of the binary encoding, telling which optional fields are set.
+const (
+	binaryGroups = 1 << iota
+	binaryHaving
+	binaryLimit
+	binaryOffset
+	binaryCursor
+	binaryTerm
+	binaryIncludeDeleted
+	binaryDistinct
+)
+
+// ErrInvalidSignature is reported by UnmarshalSigned when data was not
+// signed with the given key, e.g. because it was tampered with.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// MarshalBinary encodes s in a compact binary form, e.g. to embed it in
+// a cursor or store it in a cache. Strings are length-prefixed and
+// integers varint-encoded. Use MarshalSigned when the encoding is sent
+// to clients.
+func (s *SearchRequest) MarshalBinary() ([]byte, error) {
+	var flags byte
+	if s.Groups != nil {
+		flags |= binaryGroups
+	}
+	if s.Having != nil {
+		flags |= binaryHaving
+	}
+	if s.Limit != nil {
+		flags |= binaryLimit
+	}
+	if s.Offset != nil {
+		flags |= binaryOffset
+	}
+	if s.Cursor != nil {
+		flags |= binaryCursor
+	}
+	if s.Term != nil {
+		flags |= binaryTerm
+	}
+	if s.IncludeDeleted {
+		flags |= binaryIncludeDeleted
+	}
+	if s.Distinct {
+		flags |= binaryDistinct
+	}
+
+	buf := []byte{binaryVersion, flags}
+
+	if s.Groups != nil {
+		buf = appendGroup(buf, *s.Groups)
+	}
+	if s.Having != nil {
+		buf = appendGroup(buf, *s.Having)
+	}
+
+	buf = appendLen(buf, s.OrderBy == nil, len(s.OrderBy))
+	for _, o := range s.OrderBy {
+		buf = appendString(buf, o.Field)
+		buf = appendString(buf, string(o.Direction))
+	}
+
+	if s.Limit != nil {
+		buf = binary.AppendVarint(buf, int64(*s.Limit))
+	}
+	if s.Offset != nil {
+		buf = binary.AppendVarint(buf, int64(*s.Offset))
+	}
+	if s.Cursor != nil {
+		buf = appendString(buf, *s.Cursor)
+	}
+	if s.Term != nil {
+		buf = appendString(buf, *s.Term)
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinary decodes into s a search request encoded with
+// MarshalBinary. The decoded request is not validated: parse it again,
+// or only decode data built by the application, e.g. signed with
+// MarshalSigned.
+func (s *SearchRequest) UnmarshalBinary(data []byte) error {
+	d := &binaryDecoder{data: data}
+
+	if version := d.byte(); d.err == nil && version != binaryVersion {
+		return fmt.Errorf("unsupported binary search request version %d", version)
+	}
+
+	flags := d.byte()
+
+	var decoded SearchRequest
+	if flags&binaryGroups != 0 {
+		decoded.Groups = ptr(d.group(0))
+	}
+	if flags&binaryHaving != 0 {
+		decoded.Having = ptr(d.group(0))
+	}
+
+	if n, ok := d.len(); ok {
+		decoded.OrderBy = make([]OrderClause, n)
+		for i := range decoded.OrderBy {
+			decoded.OrderBy[i] = OrderClause{Field: d.string(), Direction: OrderDirection(d.string())}
+		}
+	}
+
+	if flags&binaryLimit != 0 {
+		decoded.Limit = ptr(d.int())
+	}
+	if flags&binaryOffset != 0 {
+		decoded.Offset = ptr(d.int())
+	}
+	if flags&binaryCursor != 0 {
+		decoded.Cursor = ptr(d.string())
+	}
+	if flags&binaryTerm != 0 {
+		decoded.Term = ptr(d.string())
+	}
+	decoded.IncludeDeleted = flags&binaryIncludeDeleted != 0
+	decoded.Distinct = flags&binaryDistinct != 0
+
+	if d.err == nil && len(d.data) > 0 {
+		d.err = errors.New("trailing data")
+	}
+	if d.err != nil {
+		return fmt.Errorf("invalid binary search request: %w", d.err)
+	}
+
+	*s = decoded
+	return nil
+}
+
+// MarshalSigned encodes s like MarshalBinary, followed by the HMAC-SHA256
+// of the encoding with key, so that UnmarshalSigned detects tampering.
+func (s *SearchRequest) MarshalSigned(key []byte) ([]byte, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	return mac.Sum(data), nil
+}
+
+// UnmarshalSigned decodes into s a search request encoded with
+// MarshalSigned, returning ErrInvalidSignature when data was not signed
+// with key.
+func (s *SearchRequest) UnmarshalSigned(data, key []byte) error {
+	if len(data) < sha256.Size {
+		return ErrInvalidSignature
+	}
+
+	data, sum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	if !hmac.Equal(sum, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	return s.UnmarshalBinary(data)
+}
+
+// appendGroup appends the encoding of g to buf.
+func appendGroup(buf []byte, g FilterGroup) []byte {
+	buf = appendString(buf, string(g.Op))
+
+	buf = appendLen(buf, g.Filters == nil, len(g.Filters))
+	for _, f := range g.Filters {
+		buf = appendString(buf, f.Field)
+		buf = appendString(buf, string(f.Op))
+		buf = appendString(buf, f.Value)
+		buf = appendLen(buf, f.Values == nil, len(f.Values))
+		for _, v := range f.Values {
+			buf = appendString(buf, v)
+		}
+		buf = appendString(buf, f.ValueField)
+	}
+
+	buf = appendLen(buf, g.Groups == nil, len(g.Groups))
+	for _, sub := range g.Groups {
+		buf = appendGroup(buf, sub)
+	}
+
+	return buf
+}
+
+// appendLen appends the length of a slice, encoded as 0 for a nil slice
+// and n+1 otherwise so that nil and empty slices round-trip.
+func appendLen(buf []byte, isNil bool, n int) []byte {
+	if isNil {
+		return binary.AppendUvarint(buf, 0)
+	}
+
+	return binary.AppendUvarint(buf, uint64(n)+1)
+}
+
+// appendString appends the length-prefixed v to buf.
+func appendString(buf []byte, v string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(v)))
+	return append(buf, v...)
+}
+
+// binaryDecoder reads the binary encoding of a search request. The
+// first error is kept in err, after which reads return zero values.
+type binaryDecoder struct {
+	data []byte
+	err  error
+}
+
+// byte reads a single byte.
+func (d *binaryDecoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.data) == 0 {
+		d.err = errors.New("unexpected end of data")
+		return 0
+	}
+
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+// uvarint reads an unsigned varint.
+func (d *binaryDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = errors.New("malformed varint")
+		return 0
+	}
+
+	d.data = d.data[n:]
+	return v
+}
+
+// int reads a signed varint.
+func (d *binaryDecoder) int() int {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Varint(d.data)
+	if n <= 0 || int64(int(v)) != v {
+		d.err = errors.New("malformed varint")
+		return 0
+	}
+
+	d.data = d.data[n:]
+	return int(v)
+}
+
+// len reads the length of a slice written by appendLen, reporting false
+// for a nil slice. Lengths beyond the remaining data are rejected, as
+// every element takes at least one byte.
+func (d *binaryDecoder) len() (int, bool) {
+	v := d.uvarint()
+	if v == 0 {
+		return 0, false
+	}
+
+	if v-1 > uint64(len(d.data)) {
+		d.err = errors.New("length out of range")
+		return 0, false
+	}
+
+	return int(v - 1), true
+}
+
+// string reads a length-prefixed string.
+func (d *binaryDecoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if n > uint64(len(d.data)) {
+		d.err = errors.New("length out of range")
+		return ""
+	}
+
+	v := string(d.data[:n])
+	d.data = d.data[n:]
+	return v
+}
+
+// group reads a filter group nested depth levels deep.
+func (d *binaryDecoder) group(depth int) FilterGroup {
+	if depth > maxBinaryDepth {
+		d.err = fmt.Errorf("filter groups nested deeper than %d levels", maxBinaryDepth)
+	}
+
+	g := FilterGroup{Op: LogicalOperator(d.string())}
+
+	if n, ok := d.len(); ok {
+		g.Filters = make([]Filter, n)
+		for i := range g.Filters {
+			f := Filter{
+				Field: d.string(),
+				Op:    RelationalOperator(d.string()),
+				Value: d.string(),
+			}
+			if n, ok := d.len(); ok {
+				f.Values = make([]string, n)
+				for j := range f.Values {
+					f.Values[j] = d.string()
+				}
+			}
+			f.ValueField = d.string()
+
+			g.Filters[i] = f
+		}
+	}
+
+	if n, ok := d.len(); ok {
+		g.Groups = make([]FilterGroup, n)
+		for i := range g.Groups {
+			g.Groups[i] = d.group(depth + 1)
+		}
+	}
+
+	return g
+}
@@ -0,0 +1,139 @@
+package qparams
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchRequestMarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		search SearchRequest
+	}{
+		{
+			name:   "with empty search",
+			search: SearchRequest{},
+		},
+		{
+			name: "with every field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "status", Op: EqualsOperator, Value: "active"},
+						{Field: "role", Op: InOperator, Values: []string{"admin", "editor"}},
+						{Field: "updated_at", Op: GreaterThanOperator, ValueField: "created_at"},
+					},
+					Groups: []FilterGroup{{
+						Op:      OrOperator,
+						Filters: []Filter{{Field: "name", Op: LikeOperator, Value: "al%"}},
+						Groups:  []FilterGroup{},
+					}},
+				},
+				Having:         &FilterGroup{Op: AndOperator, Filters: []Filter{}},
+				OrderBy:        []OrderClause{{Field: "created_at", Direction: OrderDesc}},
+				Limit:          ptr(20),
+				Offset:         ptr(-1),
+				Cursor:         ptr("abc"),
+				Term:           ptr(""),
+				IncludeDeleted: true,
+				Distinct:       true,
+			},
+		},
+		{
+			name:   "with empty order by",
+			search: SearchRequest{OrderBy: []OrderClause{}, Limit: ptr(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.search.MarshalBinary()
+			assert.NilError(t, err)
+
+			var decoded SearchRequest
+			assert.NilError(t, decoded.UnmarshalBinary(data))
+			assert.DeepEqual(t, decoded, tt.search, cmpopts.IgnoreUnexported(SearchRequest{}))
+		})
+	}
+}
+
+func TestSearchRequestUnmarshalBinaryInvalid(t *testing.T) {
+	t.Parallel()
+
+	data, err := (&SearchRequest{Term: ptr("alice")}).MarshalBinary()
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		expectedErr string
+	}{
+		{
+			name:        "with no data",
+			data:        nil,
+			expectedErr: "unexpected end of data",
+		},
+		{
+			name:        "with unknown version",
+			data:        []byte{2, 0, 0},
+			expectedErr: "unsupported binary search request version 2",
+		},
+		{
+			name:        "with truncated data",
+			data:        data[:len(data)-1],
+			expectedErr: "length out of range",
+		},
+		{
+			name:        "with trailing data",
+			data:        append(data, 0),
+			expectedErr: "trailing data",
+		},
+		{
+			name:        "with too many elements",
+			data:        []byte{binaryVersion, 0, 100},
+			expectedErr: "length out of range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search := SearchRequest{Limit: ptr(5)}
+			err := search.UnmarshalBinary(tt.data)
+			assert.ErrorContains(t, err, tt.expectedErr)
+			assert.DeepEqual(t, search, SearchRequest{Limit: ptr(5)}, cmpopts.IgnoreUnexported(SearchRequest{}))
+		})
+	}
+}
+
+func TestSearchRequestMarshalSigned(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "id", Op: GreaterThanOperator, Value: "42"}},
+		},
+		Limit: ptr(10),
+	}
+
+	data, err := search.MarshalSigned(key)
+	assert.NilError(t, err)
+
+	var decoded SearchRequest
+	assert.NilError(t, decoded.UnmarshalSigned(data, key))
+	assert.DeepEqual(t, decoded, search, cmpopts.IgnoreUnexported(SearchRequest{}))
+
+	assert.ErrorIs(t, decoded.UnmarshalSigned(data, []byte("other")), ErrInvalidSignature)
+	assert.ErrorIs(t, decoded.UnmarshalSigned(data[:10], key), ErrInvalidSignature)
+
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-sha256.Size-1]++
+	assert.ErrorIs(t, decoded.UnmarshalSigned(tampered, key), ErrInvalidSignature)
+}
@@ -0,0 +1,66 @@
+package qparams
+
+import "fmt"
+
+// BuildOptions bundles the settings of the query builders, ToSQL and
+// the other SQL helpers as well as ToElasticQuery, set at once with
+// WithBuildOptions.
+type BuildOptions struct {
+	// Strict rejects unknown logical operators, relational operators and
+	// order directions instead of rendering them as "and", "=" and "asc"
+	// like their Symbol methods do. Requests validated by the handler
+	// only carry known values; Strict catches requests built or modified
+	// by the application. An empty direction is still rendered as "asc".
+	Strict bool
+
+	// ExpandInToOr renders in filters as equalities joined by "or", e.g.
+	// (status = $1 or status = $2), for databases optimizing equality
+	// better than IN. It only applies to the SQL builders.
+	ExpandInToOr bool
+}
+
+// WithBuildOptions configures the query builders rendering the search
+// requests parsed with the options. It replaces the setting of
+// WithExpandInToOr.
+func WithBuildOptions(b BuildOptions) Option {
+	return func(o *Options) {
+		o.buildOptions = b
+	}
+}
+
+// WithExpandInToOr configures whether the SQL builders render in filters
+// as equalities joined by "or", see BuildOptions.ExpandInToOr.
+// Validation is unaffected.
+func WithExpandInToOr(value bool) Option {
+	return func(o *Options) {
+		o.buildOptions.ExpandInToOr = value
+	}
+}
+
+// checkLogical returns an error when op is unknown in strict mode.
+func (b BuildOptions) checkLogical(op LogicalOperator) error {
+	if _, ok := logicalOperators[op]; b.Strict && !ok {
+		return fmt.Errorf("unknown logical operator %q", op)
+	}
+
+	return nil
+}
+
+// checkRelational returns an error when op is unknown in strict mode.
+func (b BuildOptions) checkRelational(op RelationalOperator) error {
+	if _, ok := relationalOperators[op]; b.Strict && !ok {
+		return fmt.Errorf("unknown relational operator %q", op)
+	}
+
+	return nil
+}
+
+// checkDirection returns an error when d is neither empty, asc nor desc
+// in strict mode.
+func (b BuildOptions) checkDirection(d OrderDirection) error {
+	if b.Strict && d != "" && d != OrderAsc && d != OrderDesc {
+		return fmt.Errorf("unknown order direction %q", d)
+	}
+
+	return nil
+}
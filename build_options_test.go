@@ -0,0 +1,115 @@
+package qparams
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBuildOptionsStrict(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		search      SearchRequest
+		expectedErr string
+	}{
+		{
+			name: "with unknown logical operator",
+			search: SearchRequest{Groups: &FilterGroup{
+				Op:      "xor",
+				Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+			}},
+			expectedErr: `unknown logical operator "xor"`,
+		},
+		{
+			name: "with unknown relational operator",
+			search: SearchRequest{Groups: &FilterGroup{
+				Op:      AndOperator,
+				Filters: []Filter{{Field: "status", Op: "soundex", Value: "active"}},
+			}},
+			expectedErr: `relational operator "soundex"`,
+		},
+		{
+			name:        "with unknown order direction",
+			search:      SearchRequest{OrderBy: []OrderClause{{Field: "name", Direction: "descending"}}},
+			expectedErr: `unknown order direction "descending"`,
+		},
+		{
+			name: "with known values",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      OrOperator,
+					Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+				},
+				OrderBy: []OrderClause{{Field: "name"}, {Field: "id", Direction: OrderDesc}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			permissive := tt.search
+			permissive.options = &Options{}
+			_, _, err := permissive.AppendToQuery("SELECT * FROM users", DialectPostgres)
+			assert.NilError(t, err)
+
+			strict := tt.search
+			strict.options = &Options{buildOptions: BuildOptions{Strict: true}}
+
+			_, _, err = strict.AppendToQuery("SELECT * FROM users", DialectPostgres)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NilError(t, err)
+			}
+
+			_, err = strict.ToElasticQuery()
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NilError(t, err)
+			}
+		})
+	}
+}
+
+func TestWithBuildOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := NewOptions(WithBuildOptions(BuildOptions{Strict: true}))
+	assert.Equal(t, opts.buildOptions.Strict, true)
+}
+
+func TestWithExpandInToOr(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op: AndOperator,
+			Filters: []Filter{
+				{Field: "status", Op: InOperator, Values: []string{"active", "pending"}},
+				{Field: "role", Op: InOperator, Value: "admin"},
+				{Field: "team", Op: NotInOperator, Values: []string{"a", "b"}},
+			},
+		},
+		options: NewOptions(WithExpandInToOr(true)),
+	}
+
+	sql, args, err := search.ToSQL(DialectPostgres)
+	assert.NilError(t, err)
+	assert.Equal(t, sql, "(status = $1 or status = $2) and role = $3 and team not in ($4, $5)")
+	assert.DeepEqual(t, args, []any{"active", "pending", "admin", "a", "b"})
+
+	named, namedArgs, err := search.ToNamedSQL()
+	assert.NilError(t, err)
+	assert.Equal(t, named, "(status = :status_0 or status = :status_1) and role = :role_2 and team not in (:team_3, :team_4)")
+	assert.DeepEqual(t, namedArgs, map[string]any{
+		"status_0": "active", "status_1": "pending", "role_2": "admin", "team_3": "a", "team_4": "b",
+	})
+
+	search.options = NewOptions(WithExpandInToOr(false))
+	sql, _, err = search.ToSQL(DialectPostgres)
+	assert.NilError(t, err)
+	assert.Equal(t, sql, "status in ($1, $2) and role in ($3) and team not in ($4, $5)")
+}
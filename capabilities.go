@@ -0,0 +1,102 @@
+package qparams
+
+import (
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// capabilities is the body written by CapabilitiesHandler.
+type capabilities struct {
+	QueryParam          string               `json:"query_param"`
+	SearchMandatory     bool                 `json:"search_mandatory"`
+	FilterFields        []string             `json:"filter_fields"`
+	OrderFields         []string             `json:"order_fields"`
+	LogicalOperators    []LogicalOperator    `json:"logical_operators"`
+	RelationalOperators []RelationalOperator `json:"relational_operators"`
+	SearchTermFields    []string             `json:"search_term_fields"`
+	MaxLimit            *int                 `json:"max_limit"`
+	DefaultLimit        *int                 `json:"default_limit"`
+	MaxOrderFields      *int                 `json:"max_order_fields"`
+	MaxOffset           *int                 `json:"max_offset"`
+	OffsetDisabled      bool                 `json:"offset_disabled"`
+
+	Fields map[string]fieldCapabilities `json:"fields"`
+}
+
+// fieldCapabilities describes the per-field rules of a field in the
+// body written by CapabilitiesHandler.
+type fieldCapabilities struct {
+	Type                FieldType            `json:"type,omitempty"`
+	Enum                []string             `json:"enum,omitempty"`
+	RelationalOperators []RelationalOperator `json:"relational_operators,omitempty"`
+	OrderDirections     []OrderDirection     `json:"order_directions,omitempty"`
+}
+
+// CapabilitiesHandler creates a handler that responds with a JSON body
+// describing the search capabilities of a handler created with the same
+// opts: query parameter name, allowed fields and operators, limit
+// bounds, and the per-field type, enum, operators and order directions.
+// Clients can fetch it to build their search UI dynamically.
+func CapabilitiesHandler(opts ...Option) http.Handler {
+	options := NewOptions(opts...)
+
+	body := capabilities{
+		QueryParam:          options.QueryParam(),
+		SearchMandatory:     options.isSearchMandatory,
+		FilterFields:        options.AllowedFilterFields(),
+		OrderFields:         options.AllowedOrderFields(),
+		LogicalOperators:    options.AllowedLogicalOperators(),
+		RelationalOperators: options.AllowedRelationalOperators(),
+		SearchTermFields:    append([]string{}, options.searchTermFields...),
+		MaxLimit:            options.limit,
+		DefaultLimit:        options.fallbackLimit,
+		MaxOrderFields:      options.maxOrderFields,
+		MaxOffset:           options.maxOffset,
+		OffsetDisabled:      options.isOffsetDisabled,
+		Fields:              fieldsCapabilities(options),
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			slog.Default().ErrorContext(r.Context(), "failed to send response", slog.String("err", err.Error()))
+		}
+	})
+}
+
+// fieldsCapabilities returns the per-field rules of options, keyed by
+// field. Fields without rules are omitted.
+func fieldsCapabilities(options *Options) map[string]fieldCapabilities {
+	fields := map[string]fieldCapabilities{}
+	update := func(field string, fn func(*fieldCapabilities)) {
+		c := fields[field]
+		fn(&c)
+		fields[field] = c
+	}
+
+	for field, t := range options.fieldTypes {
+		update(field, func(c *fieldCapabilities) { c.Type = t })
+	}
+
+	for field, values := range options.fieldEnums {
+		update(field, func(c *fieldCapabilities) { c.Enum = slices.Sorted(maps.Keys(values)) })
+	}
+
+	for field, ops := range options.fieldOperators {
+		update(field, func(c *fieldCapabilities) {
+			c.RelationalOperators = slices.DeleteFunc(slices.Sorted(maps.Keys(ops)), func(op RelationalOperator) bool {
+				_, disabled := options.disabledOperators[op]
+				return disabled
+			})
+		})
+	}
+
+	for field, dirs := range options.orderFieldDirections {
+		update(field, func(c *fieldCapabilities) { c.OrderDirections = slices.Sorted(maps.Keys(dirs)) })
+	}
+
+	return fields
+}
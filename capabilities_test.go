@@ -0,0 +1,41 @@
+package qparams
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCapabilitiesHandler(t *testing.T) {
+	t.Parallel()
+
+	handler := CapabilitiesHandler(
+		WithQueryParam("s"),
+		WithFieldSpec([]FieldSpec{
+			{Name: "id", Filterable: true, Type: TypeNumber},
+			{Name: "name", Filterable: true, AllowedOps: []RelationalOperator{LikeOperator, EqualsOperator}},
+			{Name: "status", Filterable: true, Enum: []string{"pending", "active"}},
+			{Name: "created_at", Sortable: true},
+		}),
+		WithOrderFieldDirections("created_at", OrderDesc),
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(EqualsOperator, InOperator, LikeOperator),
+		WithDisabledOperators(map[RelationalOperator]string{LikeOperator: "too slow"}),
+		WithLimit(50),
+		WithDefaultLimit(20),
+	)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/search/schema", nil))
+
+	assert.Equal(t, rr.Code, http.StatusOK)
+	assert.Equal(t, rr.Header().Get("Content-Type"), "application/json")
+	assert.Equal(t, rr.Body.String(), `{"query_param":"s","search_mandatory":true,`+
+		`"filter_fields":["id","name","status"],"order_fields":["created_at"],`+
+		`"logical_operators":["and"],"relational_operators":["eq","in"],"search_term_fields":[],`+
+		`"max_limit":50,"default_limit":20,"max_order_fields":null,"max_offset":null,"offset_disabled":false,`+
+		`"fields":{"created_at":{"order_directions":["desc"]},"id":{"type":"number"},`+
+		`"name":{"relational_operators":["eq"]},"status":{"enum":["active","pending"]}}}`+"\n")
+}
@@ -0,0 +1,48 @@
+package qparams
+
+import "strconv"
+
+// Dialect identifies the SQL flavour targeted by the SQL builders.
+// It drives the placeholder style and the operators that have no
+// portable SQL form.
+type Dialect string
+
+const (
+	// DialectPostgres targets PostgreSQL, using $1, $2, ... placeholders.
+	DialectPostgres Dialect = "postgres"
+
+	// DialectMySQL targets MySQL and MariaDB, using ? placeholders.
+	DialectMySQL Dialect = "mysql"
+
+	// DialectSQLite targets SQLite, using ? placeholders.
+	DialectSQLite Dialect = "sqlite"
+)
+
+// placeholder returns the positional placeholder of the n-th
+// argument, starting from 1.
+func (d Dialect) placeholder(n int) string {
+	if d == DialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+
+	return "?"
+}
+
+// symbol returns the SQL operator rendering op in d. It falls back to
+// the dialect-independent RelationalOperator.Symbol.
+func (d Dialect) symbol(op RelationalOperator) string {
+	if op == EqualsNullSafeOperator {
+		switch d {
+		case DialectMySQL:
+			return "<=>"
+		case DialectSQLite:
+			return "is"
+		}
+	}
+
+	if op == RegexOperator && d == DialectMySQL {
+		return "regexp"
+	}
+
+	return op.Symbol()
+}
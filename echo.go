@@ -0,0 +1,15 @@
+//go:build echo
+
+package qparams
+
+import "github.com/labstack/echo/v4"
+
+// EchoMiddleware creates the middleware of NewSearchHandler as an echo
+// MiddlewareFunc, e.g.
+//
+//	e.GET("/users", listUsers, qparams.EchoMiddleware(qparams.WithFilterFields("name")))
+//
+// The parsed request is retrieved with GetSearchRequest(c.Request()).
+func EchoMiddleware(opts ...Option) echo.MiddlewareFunc {
+	return echo.WrapMiddleware(NewSearchHandler(opts...))
+}
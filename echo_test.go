@@ -0,0 +1,72 @@
+//go:build echo
+
+package qparams
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gotest.tools/v3/assert"
+)
+
+func TestEchoMiddleware(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.GET("/users", func(c echo.Context) error {
+		search := GetSearchRequest(c.Request())
+		if search == nil {
+			return c.NoContent(http.StatusNoContent)
+		}
+
+		return c.String(http.StatusOK, search.Groups.Filters[0].Value)
+	}, EchoMiddleware(
+		WithQueryParam("q"),
+		WithSearchMandatory(false),
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(EqualsOperator),
+		WithFilterFields("name"),
+	))
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "with search",
+			query:          `{"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"alice"}]}}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "alice",
+		},
+		{
+			name:           "without search",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "with invalid search",
+			query:          `{"groups":{"op":"and","filters":[{"field":"email","op":"eq","value":"alice"}]}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   http.StatusText(http.StatusBadRequest),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/users"
+			if tt.query != "" {
+				target += "?q=" + url.QueryEscape(tt.query)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+			assert.Equal(t, rec.Code, tt.expectedStatus)
+			assert.Equal(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package qparams
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ToElasticQuery renders s as an Elasticsearch search body made of plain
+// maps, ready to be marshaled to JSON.
+//
+// Filter groups become bool queries: "and" groups use must/must_not and
+// "or" groups use should. Relational operators map to term, terms, range
+// and wildcard clauses, where like and ilike patterns are translated to
+// wildcard syntax. OrderBy maps to sort, Offset to from and Limit to size.
+// Having filters and computed fields are not supported.
+func (s *SearchRequest) ToElasticQuery() (map[string]any, error) {
+	if s.Having != nil {
+		return nil, errors.New("having filters not supported")
+	}
+
+	body := map[string]any{}
+
+	if s.Groups == nil {
+		body["query"] = map[string]any{"match_all": map[string]any{}}
+	} else {
+		q, err := elasticGroup(s.Groups, s.computedFields(), s.buildOptions())
+		if err != nil {
+			return nil, err
+		}
+		body["query"] = q
+	}
+
+	if len(s.OrderBy) > 0 {
+		sort := make([]any, 0, len(s.OrderBy))
+		for _, o := range s.OrderBy {
+			if err := s.buildOptions().checkDirection(o.Direction); err != nil {
+				return nil, err
+			}
+			sort = append(sort, map[string]any{
+				o.Field: map[string]any{"order": o.Direction.Symbol()},
+			})
+		}
+		body["sort"] = sort
+	}
+
+	if s.Offset != nil {
+		body["from"] = *s.Offset
+	}
+
+	if s.Limit != nil {
+		body["size"] = *s.Limit
+	}
+
+	return body, nil
+}
+
+// elasticGroup renders g as a bool query. Filters on computed fields
+// are rejected.
+func elasticGroup(g *FilterGroup, computed map[string]string, build BuildOptions) (map[string]any, error) {
+	if err := build.checkLogical(g.Op); err != nil {
+		return nil, err
+	}
+
+	var clauses, negated []any
+
+	for _, f := range g.Filters {
+		if _, ok := computed[f.Field]; ok {
+			return nil, fmt.Errorf("filter on computed field %q not supported", f.Field)
+		}
+
+		c, err := elasticFilter(f)
+		if err != nil {
+			return nil, err
+		}
+
+		if f.Op == NotEqualsOperator || f.Op == NotInOperator {
+			negated = append(negated, c)
+		} else {
+			clauses = append(clauses, c)
+		}
+	}
+
+	for i := range g.Groups {
+		c, err := elasticGroup(&g.Groups[i], computed, build)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, c)
+	}
+
+	query := map[string]any{}
+
+	if g.Op == OrOperator {
+		for _, c := range negated {
+			clauses = append(clauses, map[string]any{
+				"bool": map[string]any{"must_not": []any{c}},
+			})
+		}
+		if len(clauses) > 0 {
+			query["should"] = clauses
+			query["minimum_should_match"] = 1
+		}
+	} else {
+		if len(clauses) > 0 {
+			query["must"] = clauses
+		}
+		if len(negated) > 0 {
+			query["must_not"] = negated
+		}
+	}
+
+	return map[string]any{"bool": query}, nil
+}
+
+// elasticFilter renders a single filter as a leaf query. Negated
+// operators are rendered as their positive form, the caller is in
+// charge of placing them in a must_not clause.
+func elasticFilter(f Filter) (map[string]any, error) {
+	if f.ValueField != "" {
+		return nil, fmt.Errorf("filter on field %q: comparison with another field not supported", f.Field)
+	}
+
+	switch f.Op {
+	case EqualsOperator, NotEqualsOperator, EqualsNullSafeOperator:
+		return map[string]any{"term": map[string]any{f.Field: f.Value}}, nil
+	case FullTextOperator:
+		return map[string]any{
+			"match": map[string]any{f.Field: map[string]any{"query": f.Value, "operator": "and"}},
+		}, nil
+	case IEqualsOperator:
+		return map[string]any{
+			"term": map[string]any{f.Field: map[string]any{"value": f.Value, "case_insensitive": true}},
+		}, nil
+	case BetweenOperator:
+		values := f.values()
+		if len(values) != 2 {
+			return nil, fmt.Errorf("%q filter requires exactly two values", f.Op)
+		}
+		return map[string]any{
+			"range": map[string]any{f.Field: map[string]any{"gte": values[0], "lte": values[1]}},
+		}, nil
+	case RegexOperator:
+		return map[string]any{"regexp": map[string]any{f.Field: map[string]any{"value": f.Value}}}, nil
+	case IRegexOperator:
+		return map[string]any{
+			"regexp": map[string]any{f.Field: map[string]any{"value": f.Value, "case_insensitive": true}},
+		}, nil
+	case GreaterThanOperator, GreaterThanEqualsOperator, LowerThanOperator, LowerThanEqualsOperator:
+		return map[string]any{
+			"range": map[string]any{f.Field: map[string]any{f.Op.String(): f.Value}},
+		}, nil
+	case LikeOperator:
+		return map[string]any{
+			"wildcard": map[string]any{f.Field: map[string]any{"value": likeToWildcard(f.Value)}},
+		}, nil
+	case ILikeOperator:
+		return map[string]any{
+			"wildcard": map[string]any{f.Field: map[string]any{
+				"value":            likeToWildcard(f.Value),
+				"case_insensitive": true,
+			}},
+		}, nil
+	case ArrayContainsOperator:
+		must := []any{}
+		for _, v := range f.values() {
+			must = append(must, map[string]any{"term": map[string]any{f.Field: v}})
+		}
+		return map[string]any{"bool": map[string]any{"must": must}}, nil
+	case IsNotNullOperator:
+		return map[string]any{"exists": map[string]any{"field": f.Field}}, nil
+	case IsNullOperator:
+		return map[string]any{"bool": map[string]any{
+			"must_not": []any{map[string]any{"exists": map[string]any{"field": f.Field}}},
+		}}, nil
+	case InOperator, NotInOperator:
+		terms := []any{}
+		for _, v := range f.values() {
+			terms = append(terms, v)
+		}
+		return map[string]any{"terms": map[string]any{f.Field: terms}}, nil
+	default:
+		return nil, fmt.Errorf("relational operator %q not supported", f.Op)
+	}
+}
+
+// likeToWildcard translates a like pattern to the Elasticsearch wildcard
+// syntax: % becomes *, _ becomes ? and escaped like wildcards are kept
+// literal.
+func likeToWildcard(pattern string) string {
+	var sb strings.Builder
+
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			if r == '*' || r == '?' || r == '\\' {
+				sb.WriteRune('\\')
+			}
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			sb.WriteRune('*')
+		case r == '_':
+			sb.WriteRune('?')
+		case r == '*' || r == '?':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,187 @@
+package qparams
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchRequestToElasticQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		search      SearchRequest
+		expected    map[string]any
+		expectedErr string
+	}{
+		{
+			name:   "without groups",
+			search: SearchRequest{},
+			expected: map[string]any{
+				"query": map[string]any{"match_all": map[string]any{}},
+			},
+		},
+		{
+			name: "with and group",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "status", Op: EqualsOperator, Value: "active"},
+						{Field: "role", Op: NotEqualsOperator, Value: "guest"},
+						{Field: "age", Op: GreaterThanEqualsOperator, Value: "18"},
+					},
+				},
+			},
+			expected: map[string]any{
+				"query": map[string]any{"bool": map[string]any{
+					"must": []any{
+						map[string]any{"term": map[string]any{"status": "active"}},
+						map[string]any{"range": map[string]any{"age": map[string]any{"gte": "18"}}},
+					},
+					"must_not": []any{
+						map[string]any{"term": map[string]any{"role": "guest"}},
+					},
+				}},
+			},
+		},
+		{
+			name: "with or group and wildcards",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: OrOperator,
+					Filters: []Filter{
+						{Field: "name", Op: LikeOperator, Value: `a_c%`},
+						{Field: "email", Op: ILikeOperator, Value: `%\%*`},
+						{Field: "role", Op: NotEqualsOperator, Value: "guest"},
+					},
+				},
+			},
+			expected: map[string]any{
+				"query": map[string]any{"bool": map[string]any{
+					"should": []any{
+						map[string]any{"wildcard": map[string]any{"name": map[string]any{"value": "a?c*"}}},
+						map[string]any{"wildcard": map[string]any{"email": map[string]any{
+							"value":            `*%\*`,
+							"case_insensitive": true,
+						}}},
+						map[string]any{"bool": map[string]any{"must_not": []any{
+							map[string]any{"term": map[string]any{"role": "guest"}},
+						}}},
+					},
+					"minimum_should_match": 1,
+				}},
+			},
+		},
+		{
+			name: "with order and pagination",
+			search: SearchRequest{
+				OrderBy: []OrderClause{{Field: "created_at", Direction: OrderDesc}},
+				Limit:   ptr(20),
+				Offset:  ptr(40),
+			},
+			expected: map[string]any{
+				"query": map[string]any{"match_all": map[string]any{}},
+				"sort": []any{
+					map[string]any{"created_at": map[string]any{"order": "desc"}},
+				},
+				"from": 40,
+				"size": 20,
+			},
+		},
+		{
+			name: "with between",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "price", Op: BetweenOperator, Values: []string{"1", "100"}}},
+				},
+			},
+			expected: map[string]any{
+				"query": map[string]any{"bool": map[string]any{
+					"must": []any{
+						map[string]any{"range": map[string]any{"price": map[string]any{"gte": "1", "lte": "100"}}},
+					},
+				}},
+			},
+		},
+		{
+			name: "with regex",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "email", Op: IRegexOperator, Value: ".*@example"}},
+				},
+			},
+			expected: map[string]any{
+				"query": map[string]any{"bool": map[string]any{
+					"must": []any{
+						map[string]any{"regexp": map[string]any{"email": map[string]any{
+							"value":            ".*@example",
+							"case_insensitive": true,
+						}}},
+					},
+				}},
+			},
+		},
+		{
+			name: "with case-insensitive equality",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "email", Op: IEqualsOperator, Value: "Alice@Example.com"}},
+				},
+			},
+			expected: map[string]any{
+				"query": map[string]any{"bool": map[string]any{
+					"must": []any{
+						map[string]any{"term": map[string]any{"email": map[string]any{
+							"value":            "Alice@Example.com",
+							"case_insensitive": true,
+						}}},
+					},
+				}},
+			},
+		},
+		{
+			name: "with full-text match",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "body", Op: FullTextOperator, Value: "quick fox"}},
+				},
+			},
+			expected: map[string]any{
+				"query": map[string]any{"bool": map[string]any{
+					"must": []any{
+						map[string]any{"match": map[string]any{"body": map[string]any{"query": "quick fox", "operator": "and"}}},
+					},
+				}},
+			},
+		},
+		{
+			name: "with unknown operator",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: RelationalOperator("foo"), Value: "x"}},
+				},
+			},
+			expectedErr: `relational operator "foo" not supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := tt.search.ToElasticQuery()
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, q, tt.expected)
+		})
+	}
+}
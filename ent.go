@@ -0,0 +1,225 @@
+//go:build ent
+
+package qparams
+
+import (
+	"fmt"
+	"strings"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+)
+
+// EntPredicate converts the root filter group of s into a function usable
+// in the Where method of ent queries, e.g.
+//
+//	client.User.Query().Where(predicate.User(s.EntPredicate()))
+//
+// Columns are qualified with the table of the selector. A request without
+// filters returns a no-op function. A Distinct request also makes the
+// selector distinct. Computed fields and paths inside JSONB fields are
+// not supported and are added as errors to the selector, failing the
+// query. With BuildOptions.Strict, unknown operators are added as
+// errors too.
+func (s *SearchRequest) EntPredicate() func(*sql.Selector) {
+	return func(sel *sql.Selector) {
+		if s.Distinct {
+			sel.Distinct()
+		}
+
+		if s.Groups == nil {
+			return
+		}
+
+		sel.Where(entGroupPredicate(sel, s.Groups, s.computedFields(), s.buildOptions()))
+	}
+}
+
+// EntOrder converts the order by clauses of s into a function usable
+// in the Order method of ent queries. Computed fields and paths inside
+// JSONB fields are added as errors to the selector, as are unknown
+// directions with BuildOptions.Strict.
+func (s *SearchRequest) EntOrder() func(*sql.Selector) {
+	return func(sel *sql.Selector) {
+		for _, o := range s.OrderBy {
+			if err := s.buildOptions().checkDirection(o.Direction); err != nil {
+				sel.AddError(err)
+			}
+		}
+
+		for _, f := range s.SortFields() {
+			col := entColumn(sel, f.Field, s.computedFields())
+			if f.Desc {
+				sel.OrderBy(sql.Desc(col))
+			} else {
+				sel.OrderBy(sql.Asc(col))
+			}
+		}
+	}
+}
+
+// EntPaginate converts the limit and offset of s into a function usable
+// in the Modify method of ent queries.
+func (s *SearchRequest) EntPaginate() func(*sql.Selector) {
+	return func(sel *sql.Selector) {
+		if s.Limit != nil {
+			sel.Limit(*s.Limit)
+		}
+		if s.Offset != nil {
+			sel.Offset(*s.Offset)
+		}
+	}
+}
+
+// entColumn returns the column of field qualified with the table of
+// sel. Computed fields and paths inside JSONB fields are added as errors
+// to the selector, as they are not plain columns.
+func entColumn(sel *sql.Selector, field string, computed map[string]string) string {
+	if _, ok := computed[field]; ok {
+		sel.AddError(fmt.Errorf("computed field %q not supported", field))
+	} else if strings.Contains(field, ".") {
+		sel.AddError(fmt.Errorf("JSONB path %q not supported", field))
+	}
+
+	return sel.C(field)
+}
+
+// entGroupPredicate builds the predicate of g, combining its filters and
+// nested groups with the group logical operator. An empty group is an
+// always true predicate.
+func entGroupPredicate(sel *sql.Selector, g *FilterGroup, computed map[string]string, build BuildOptions) *sql.Predicate {
+	if g.isEmpty() {
+		return sql.ExprP("1=1")
+	}
+
+	if err := build.checkLogical(g.Op); err != nil {
+		sel.AddError(err)
+	}
+
+	var preds []*sql.Predicate
+
+	for _, f := range g.Filters {
+		preds = append(preds, entFilterPredicate(sel, f, computed, build))
+	}
+
+	for i := range g.Groups {
+		preds = append(preds, entGroupPredicate(sel, &g.Groups[i], computed, build))
+	}
+
+	if g.Op == OrOperator {
+		return sql.Or(preds...)
+	}
+
+	return sql.And(preds...)
+}
+
+// entFilterPredicate maps a single filter to the corresponding ent predicate.
+func entFilterPredicate(sel *sql.Selector, f Filter, computed map[string]string, build BuildOptions) *sql.Predicate {
+	if err := build.checkRelational(f.Op); err != nil {
+		sel.AddError(err)
+	}
+
+	col := entColumn(sel, f.Field, computed)
+
+	if f.ValueField != "" {
+		other := entColumn(sel, f.ValueField, computed)
+
+		switch f.Op {
+		case NotEqualsOperator:
+			return sql.ColumnsNEQ(col, other)
+		case GreaterThanOperator:
+			return sql.ColumnsGT(col, other)
+		case GreaterThanEqualsOperator:
+			return sql.ColumnsGTE(col, other)
+		case LowerThanOperator:
+			return sql.ColumnsLT(col, other)
+		case LowerThanEqualsOperator:
+			return sql.ColumnsLTE(col, other)
+		default:
+			return sql.ColumnsEQ(col, other)
+		}
+	}
+
+	switch f.Op {
+	case NotEqualsOperator:
+		return sql.NEQ(col, f.Value)
+	case GreaterThanOperator:
+		return sql.GT(col, f.Value)
+	case GreaterThanEqualsOperator:
+		return sql.GTE(col, f.Value)
+	case LowerThanOperator:
+		return sql.LT(col, f.Value)
+	case LowerThanEqualsOperator:
+		return sql.LTE(col, f.Value)
+	case LikeOperator:
+		return sql.Like(col, f.Value)
+	case ILikeOperator:
+		return sql.P(func(b *sql.Builder) {
+			b.WriteString(col).WriteString(" ILIKE ").Arg(f.Value)
+		})
+	case FullTextOperator:
+		// the full-text configuration of the field is not available
+		// here, the server default_text_search_config is used
+		return sql.P(func(b *sql.Builder) {
+			b.WriteString("to_tsvector(").WriteString(col).WriteString(") @@ plainto_tsquery(").Arg(f.Value).WriteString(")")
+		})
+	case IEqualsOperator:
+		return sql.P(func(b *sql.Builder) {
+			b.WriteString("LOWER(").WriteString(col).WriteString(") = LOWER(").Arg(f.Value).WriteString(")")
+		})
+	case BetweenOperator:
+		// the bounds are checked by the validation of the request
+		values := f.values()
+		return sql.And(sql.GTE(col, values[0]), sql.LTE(col, values[len(values)-1]))
+	case RegexOperator:
+		return sql.P(func(b *sql.Builder) {
+			op := " ~ "
+			if b.Dialect() == dialect.MySQL {
+				op = " REGEXP "
+			}
+			b.WriteString(col).WriteString(op).Arg(f.Value)
+		})
+	case IRegexOperator:
+		return sql.P(func(b *sql.Builder) {
+			if b.Dialect() == dialect.MySQL {
+				b.WriteString("REGEXP_LIKE(").WriteString(col).WriteString(", ").Arg(f.Value).WriteString(", 'i')")
+				return
+			}
+			b.WriteString(col).WriteString(" ~* ").Arg(f.Value)
+		})
+	case InOperator:
+		var args []any
+		for _, v := range f.values() {
+			args = append(args, v)
+		}
+		return sql.In(col, args...)
+	case NotInOperator:
+		var args []any
+		for _, v := range f.values() {
+			args = append(args, v)
+		}
+		return sql.NotIn(col, args...)
+	case ArrayContainsOperator:
+		var args []any
+		for _, v := range f.values() {
+			args = append(args, v)
+		}
+		return sql.P(func(b *sql.Builder) {
+			b.WriteString(col).WriteString(" @> ARRAY[").Args(args...).WriteString("]")
+		})
+	case IsNullOperator:
+		return sql.IsNull(col)
+	case IsNotNullOperator:
+		return sql.NotNull(col)
+	case EqualsNullSafeOperator:
+		return sql.P(func(b *sql.Builder) {
+			op := " IS NOT DISTINCT FROM "
+			if b.Dialect() == dialect.MySQL {
+				op = " <=> "
+			}
+			b.WriteString(col).WriteString(op).Arg(f.Value)
+		})
+	default:
+		return sql.EQ(col, f.Value)
+	}
+}
@@ -0,0 +1,176 @@
+//go:build ent
+
+package qparams
+
+import (
+	"testing"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchRequestEnt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		search       SearchRequest
+		expected     string
+		expectedArgs []any
+	}{
+		{
+			name:     "without filters",
+			search:   SearchRequest{},
+			expected: `SELECT * FROM "users"`,
+		},
+		{
+			name: "with filters and nested group",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "status", Op: EqualsOperator, Value: "active"},
+						{Field: "role", Op: InOperator, Values: []string{"admin", "editor"}},
+					},
+					Groups: []FilterGroup{{
+						Op: OrOperator,
+						Filters: []Filter{
+							{Field: "age", Op: GreaterThanOperator, Value: "18"},
+							{Field: "deleted_at", Op: IsNullOperator},
+						},
+					}},
+				},
+			},
+			expected:     `SELECT * FROM "users" WHERE "users"."status" = $1 AND "users"."role" IN ($2, $3) AND ("users"."age" > $4 OR "users"."deleted_at" IS NULL)`,
+			expectedArgs: []any{"active", "admin", "editor", "18"},
+		},
+		{
+			name: "with value field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "updated_at", Op: GreaterThanOperator, ValueField: "created_at"}},
+				},
+			},
+			expected: `SELECT * FROM "users" WHERE "users"."updated_at" > "users"."created_at"`,
+		},
+		{
+			name: "with order, pagination and distinct",
+			search: SearchRequest{
+				OrderBy:  []OrderClause{{Field: "name"}, {Field: "id", Direction: OrderDesc}},
+				Limit:    ptr(10),
+				Offset:   ptr(20),
+				Distinct: true,
+			},
+			expected: `SELECT DISTINCT * FROM "users" ORDER BY "users"."name" ASC, "users"."id" DESC LIMIT 10 OFFSET 20`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel := sql.Dialect(dialect.Postgres).Select("*").From(sql.Table("users"))
+			tt.search.EntPredicate()(sel)
+			tt.search.EntOrder()(sel)
+			tt.search.EntPaginate()(sel)
+
+			query, args := sel.Query()
+			assert.NilError(t, sel.Err())
+			assert.Equal(t, query, tt.expected)
+			assert.DeepEqual(t, args, tt.expectedArgs)
+		})
+	}
+}
+
+func TestSearchRequestEntStrict(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "status", Op: "soundex", Value: "active"}},
+		},
+		OrderBy: []OrderClause{{Field: "name", Direction: "descending"}},
+	}
+
+	sel := sql.Dialect(dialect.Postgres).Select("*").From(sql.Table("users"))
+	search.EntPredicate()(sel)
+	search.EntOrder()(sel)
+	assert.NilError(t, sel.Err())
+
+	search.options = NewOptions(WithBuildOptions(BuildOptions{Strict: true}))
+
+	sel = sql.Dialect(dialect.Postgres).Select("*").From(sql.Table("users"))
+	search.EntPredicate()(sel)
+	search.EntOrder()(sel)
+	assert.ErrorContains(t, sel.Err(), `unknown relational operator "soundex"`)
+	assert.ErrorContains(t, sel.Err(), `unknown order direction "descending"`)
+}
+
+func TestSearchRequestEntUnsupportedFields(t *testing.T) {
+	t.Parallel()
+
+	opts := NewOptions(
+		WithFilterFields("data"),
+		WithFieldType("data", TypeJSONB),
+		WithComputedField("name_ci", "lower(name) = lower(?)"),
+	)
+
+	tests := []struct {
+		name        string
+		search      SearchRequest
+		expectedErr string
+	}{
+		{
+			name: "with computed field filter",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name_ci", Op: EqualsOperator, Value: "bob"}},
+				},
+			},
+			expectedErr: `computed field "name_ci" not supported`,
+		},
+		{
+			name: "with JSONB path filter",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "data.country", Op: EqualsOperator, Value: "IT"}},
+				},
+			},
+			expectedErr: `JSONB path "data.country" not supported`,
+		},
+		{
+			name: "with JSONB path value field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "country", Op: EqualsOperator, ValueField: "data.country"}},
+				},
+			},
+			expectedErr: `JSONB path "data.country" not supported`,
+		},
+		{
+			name:        "with JSONB path order",
+			search:      SearchRequest{OrderBy: []OrderClause{{Field: "data.country"}}},
+			expectedErr: `JSONB path "data.country" not supported`,
+		},
+		{
+			name:        "with computed field order",
+			search:      SearchRequest{OrderBy: []OrderClause{{Field: "name_ci"}}},
+			expectedErr: `computed field "name_ci" not supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.search.options = opts
+
+			sel := sql.Dialect(dialect.Postgres).Select("*").From(sql.Table("users"))
+			tt.search.EntPredicate()(sel)
+			tt.search.EntOrder()(sel)
+			assert.ErrorContains(t, sel.Err(), tt.expectedErr)
+		})
+	}
+}
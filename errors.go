@@ -0,0 +1,182 @@
+package qparams
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrPayloadTooLarge is reported when the search payload exceeds the
+// size configured with WithMaxPayloadSize.
+var ErrPayloadTooLarge = errors.New("search payload too large")
+
+// InvalidJSONError is reported when the search payload cannot be
+// decoded. Raw holds the offending payload so that an ErrorHandler can
+// log it, it is not part of the error message as it may carry
+// sensitive data.
+type InvalidJSONError struct {
+	Raw string
+	Err error
+}
+
+// Error returns the message of the decode error.
+func (e *InvalidJSONError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the decode error.
+func (e *InvalidJSONError) Unwrap() error {
+	return e.Err
+}
+
+// TruncatedRaw returns Raw cut to at most n bytes, with an ellipsis
+// appended when it was cut, for logging large payloads.
+func (e *InvalidJSONError) TruncatedRaw(n int) string {
+	if n < 0 || len(e.Raw) <= n {
+		return e.Raw
+	}
+
+	return strings.ToValidUTF8(e.Raw[:n], "") + "..."
+}
+
+// RequestError is the error passed to the ErrorHandler when a search
+// request is rejected. It wraps the underlying error and carries the
+// HTTP status suggested for the response:
+//
+//   - 400 Bad Request for a missing or malformed payload;
+//   - 413 Payload Too Large for an oversized payload;
+//   - 422 Unprocessable Entity for a payload failing validation;
+//   - 503 Service Unavailable when the request context is done.
+type RequestError struct {
+	status int
+	err    error
+}
+
+// newRequestError wraps err with the given suggested status.
+func newRequestError(status int, err error) *RequestError {
+	return &RequestError{status: status, err: err}
+}
+
+// Error returns the message of the wrapped error.
+func (e *RequestError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *RequestError) Unwrap() error {
+	return e.err
+}
+
+// StatusCode returns the HTTP status suggested for the response.
+func (e *RequestError) StatusCode() int {
+	return e.status
+}
+
+// StatusCode returns the HTTP status suggested by err. It falls back
+// to 400 Bad Request when err does not wrap a RequestError.
+func StatusCode(err error) int {
+	var re *RequestError
+	if errors.As(err, &re) {
+		return re.StatusCode()
+	}
+
+	return http.StatusBadRequest
+}
+
+// NonFieldErrorsKey is the key under which FieldErrors reports the
+// errors not tied to a field.
+const NonFieldErrorsKey = "non_field_errors"
+
+// ValidationError is reported when a search request fails validation.
+// Field is the field of the offending filter or order by clause, or the
+// payload key of the offending value (e.g. "limit"), and is empty for
+// errors not tied to a field.
+type ValidationError struct {
+	Field string
+	Err   error
+}
+
+// Error returns the message of the validation error.
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the validation error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors maps the validation errors in err to their field, e.g.
+// for form-style responses:
+//
+//	{"errors":{"limit":"limit must be null or >= 0","status":"field \"status\" not allowed in filters"}}
+//
+// Every error collected with WithCollectAllErrors is reported, messages
+// of the same field are joined with "; ". Errors not tied to a field,
+// other errors included, are reported under NonFieldErrorsKey. It
+// returns nil when err is nil.
+func FieldErrors(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+
+	fields := map[string]string{}
+	add := func(field, msg string) {
+		if field == "" {
+			field = NonFieldErrorsKey
+		}
+		if prev, ok := fields[field]; ok {
+			msg = prev + "; " + msg
+		}
+		fields[field] = msg
+	}
+
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case *ValidationError:
+			add(e.Field, e.Err.Error())
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		default:
+			if inner := errors.Unwrap(err); inner != nil {
+				walk(inner)
+				return
+			}
+			add("", err.Error())
+		}
+	}
+	walk(err)
+
+	return fields
+}
+
+// validationErrors collects the errors of a search request. Unless
+// isCollectAll is set, validation stops at the first error.
+type validationErrors struct {
+	errs         []error
+	isCollectAll bool
+}
+
+// add records err, if not nil, as a ValidationError on field and
+// reports whether validation must stop.
+func (v *validationErrors) add(field string, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	v.errs = append(v.errs, &ValidationError{Field: field, Err: err})
+	return !v.isCollectAll
+}
+
+// err returns the recorded error, the recorded errors joined with
+// errors.Join when there are several, or nil.
+func (v *validationErrors) err() error {
+	if len(v.errs) == 1 {
+		return v.errs[0]
+	}
+
+	return errors.Join(v.errs...)
+}
@@ -0,0 +1,192 @@
+package qparams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestStatusCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "with plain error",
+			err:      errors.New("boom"),
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "with request error",
+			err:      newRequestError(http.StatusRequestEntityTooLarge, ErrPayloadTooLarge),
+			expected: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "with wrapped request error",
+			err:      fmt.Errorf("wrapped: %w", newRequestError(http.StatusUnprocessableEntity, errors.New("invalid"))),
+			expected: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, StatusCode(tt.err), tt.expected)
+		})
+	}
+}
+
+func TestRequestErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	errHandler := func(w http.ResponseWriter, r *http.Request, err error) {
+		http.Error(w, err.Error(), StatusCode(err))
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := NewSearchHandler(
+		WithErrorHandler(errHandler),
+		WithMaxPayloadSize(32),
+		WithLimit(10),
+	)(next)
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedCode int
+	}{
+		{
+			name:         "with missing search",
+			path:         "/search",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "with oversized payload",
+			path:         "/search?q=" + url.QueryEscape(`{"limit":1,"offset":1000000000000000}`),
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:         "with malformed payload",
+			path:         "/search?q={notvalidJSON}",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "with invalid payload",
+			path:         "/search?q=" + url.QueryEscape(`{"limit":100}`),
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "with valid payload",
+			path:         "/search?q=" + url.QueryEscape(`{"limit":5}`),
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, rr.Code, tt.expectedCode)
+		})
+	}
+}
+
+func TestParseSearchRequestWithDoneContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q="+url.QueryEscape(`{"limit":5}`), nil).WithContext(ctx)
+
+	_, err := parseSearchRequest(req, NewOptions())
+
+	assert.Assert(t, errors.Is(err, context.DeadlineExceeded))
+	assert.ErrorContains(t, err, "search request aborted: context deadline exceeded")
+	assert.Equal(t, StatusCode(err), http.StatusServiceUnavailable)
+}
+
+func TestInvalidJSONError(t *testing.T) {
+	t.Parallel()
+
+	var got error
+	errHandler := func(w http.ResponseWriter, r *http.Request, err error) {
+		got = err
+		http.Error(w, err.Error(), StatusCode(err))
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := NewSearchHandler(
+		WithQueryParam("q"),
+		WithErrorHandler(errHandler),
+	)(next)
+
+	raw := `{"limit":"secret"}`
+	req := httptest.NewRequest(http.MethodGet, "/?q="+url.QueryEscape(raw), nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, rec.Code, http.StatusBadRequest)
+	assert.Assert(t, !strings.Contains(rec.Body.String(), "secret"))
+
+	var jsonErr *InvalidJSONError
+	assert.Assert(t, errors.As(got, &jsonErr))
+	assert.Equal(t, jsonErr.Raw, raw)
+	assert.Equal(t, jsonErr.TruncatedRaw(8), `{"limit"...`)
+	assert.Equal(t, jsonErr.TruncatedRaw(100), raw)
+	assert.Equal(t, jsonErr.TruncatedRaw(-1), raw)
+}
+
+func TestFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected map[string]string
+	}{
+		{
+			name:     "with nil error",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "with plain error",
+			err:      errors.New("boom"),
+			expected: map[string]string{NonFieldErrorsKey: "boom"},
+		},
+		{
+			name: "with wrapped validation error",
+			err: newRequestError(http.StatusUnprocessableEntity,
+				&ValidationError{Field: "limit", Err: errors.New("limit must be null or >= 0")}),
+			expected: map[string]string{"limit": "limit must be null or >= 0"},
+		},
+		{
+			name: "with joined validation errors",
+			err: newRequestError(http.StatusUnprocessableEntity, errors.Join(
+				&ValidationError{Field: "status", Err: errors.New("a")},
+				&ValidationError{Err: errors.New("b")},
+				&ValidationError{Field: "status", Err: errors.New("c")},
+			)),
+			expected: map[string]string{"status": "a; c", NonFieldErrorsKey: "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.DeepEqual(t, FieldErrors(tt.err), tt.expected)
+		})
+	}
+}
@@ -17,7 +17,7 @@ func main() {
 	qparams.SetDefaultFilterFields("id")
 	qparams.SetDefaultOrderFields("id")
 	qparams.SetDefaultErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), qparams.StatusCode(err))
 	})
 
 	search := qparams.NewSearchHandler(
@@ -28,8 +28,9 @@ func main() {
 		// Set the default limit for this handler (override default limit)
 		qparams.WithLimit(10),
 	)
-	// Wrap your handler (in this case usersHandler) with the search handler
-	mux.Handle("GET /api/v1/users", search(&usersHandler{}))
+	// Wrap your handler (in this case usersHandler) with the search handler.
+	// RequireSearch responds with 204 No Content when no search is provided
+	mux.Handle("GET /api/v1/users", search(qparams.RequireSearch(&usersHandler{})))
 
 	log.Fatal(http.ListenAndServe(":8080", mux))
 }
@@ -39,11 +40,6 @@ type usersHandler struct{}
 func (h usersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Retrieve your search and use it to filter, sort and paginate the requested data
 	s := qparams.GetSearchRequest(r)
-	if s == nil {
-		log.Println("no search provided")
-		w.WriteHeader(http.StatusNoContent)
-		return
-	}
 
 	log.Printf("search request: %+v", *s)
 
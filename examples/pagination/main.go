@@ -13,7 +13,7 @@ func main() {
 
 	// Set default for all search handler
 	qparams.SetDefaultSearchMandatory(true) // The api consumer must send query param for the search
-	qparams.SetDefaultLimit(10)             // By setting a limit, the consumer must send it and must be between 0 and given number (included)
+	qparams.SetDefaultLimit(10)             // By setting a limit, the consumer limit must be between 0 and given number (included). If omitted, the given number is used
 
 	// Wrap your handler (in this case usersHandler) with the search handler.
 	// Without defining the filter and order fields, the api consumer can only paginate the data.
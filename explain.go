@@ -0,0 +1,51 @@
+package qparams
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// explainResponse is the body written by ExplainHandler.
+type explainResponse struct {
+	// Search is the parsed and validated search request, nil when the
+	// search payload is missing and not mandatory.
+	Search *SearchRequest `json:"search"`
+
+	// SQL is the condition rendered by ToNamedSQL.
+	SQL string `json:"sql"`
+
+	// Args holds the named arguments referenced by SQL.
+	Args map[string]any `json:"args"`
+}
+
+// ExplainHandler creates a handler that parses and validates the search
+// payload exactly like NewSearchHandler, then responds with a JSON body
+// containing the normalized SearchRequest and the SQL condition with its
+// arguments as rendered by ToNamedSQL. Nothing is executed, it is meant
+// as a debugging aid for clients building search payloads.
+func ExplainHandler(opts ...Option) http.Handler {
+	options := NewOptions(opts...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		search, err := parseSearchRequest(r, options)
+		if err != nil {
+			options.errorHandler(w, r, err)
+			return
+		}
+
+		res := explainResponse{Search: search, Args: map[string]any{}}
+		if search != nil {
+			res.SQL, res.Args, err = search.ToNamedSQL()
+			if err != nil {
+				options.errorHandler(w, r, err)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			slog.Default().ErrorContext(r.Context(), "failed to send response", slog.String("err", err.Error()))
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package qparams
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestExplainHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		path         string
+		handler      http.Handler
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "with missing mandatory search",
+			path:         "/explain",
+			handler:      ExplainHandler(),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Bad Request",
+		},
+		{
+			name:         "with missing optional search",
+			path:         "/explain",
+			handler:      ExplainHandler(WithSearchMandatory(false)),
+			expectedCode: http.StatusOK,
+			expectedBody: `{"search":null,"sql":"","args":{}}` + "\n",
+		},
+		{
+			name: "with valid search",
+			path: "/explain?q=" + url.QueryEscape(`{"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"alice"}]},"limit":5}`),
+			handler: ExplainHandler(
+				WithFilterFields("name"),
+				WithLogicalOperators(AndOperator),
+				WithRelationalOperators(EqualsOperator),
+			),
+			expectedCode: http.StatusOK,
+			expectedBody: `{"search":{"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"alice"}]},"limit":5},"sql":"name = :name_0","args":{"name_0":"alice"}}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, rr.Code, tt.expectedCode)
+			assert.Equal(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
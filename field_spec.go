@@ -0,0 +1,64 @@
+package qparams
+
+// FieldSpec declares in one place how a field can be used in search
+// requests. Specs are typically loaded from a configuration file at
+// startup and passed to WithFieldSpec.
+type FieldSpec struct {
+	// Name is the field name used in search payloads.
+	Name string `json:"name" yaml:"name"`
+
+	// Type is the optional type of the field, see WithFieldType.
+	Type FieldType `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// AllowedOps restricts the relational operators allowed on the
+	// field. When empty, every operator allowed by the handler is.
+	AllowedOps []RelationalOperator `json:"allowed_ops,omitempty" yaml:"allowed_ops,omitempty"`
+
+	// Sortable reports whether the field can be used in order by.
+	Sortable bool `json:"sortable,omitempty" yaml:"sortable,omitempty"`
+
+	// Filterable reports whether the field can be used in filters.
+	Filterable bool `json:"filterable,omitempty" yaml:"filterable,omitempty"`
+
+	// Enum restricts the filter values of the field, see WithFieldEnum.
+	Enum []string `json:"enum,omitempty" yaml:"enum,omitempty"`
+}
+
+// WithFieldSpec configures the filter and order fields, and their
+// per-field rules, from specs. It replaces the filter and order fields
+// set by previous options or by the package defaults.
+func WithFieldSpec(specs []FieldSpec) Option {
+	return func(o *Options) {
+		o.allowedFilterFields = map[string]struct{}{}
+		o.allowedOrderFields = map[string]struct{}{}
+
+		for _, spec := range specs {
+			if spec.Filterable {
+				o.allowedFilterFields[spec.Name] = struct{}{}
+			}
+
+			if spec.Sortable {
+				o.allowedOrderFields[spec.Name] = struct{}{}
+			}
+
+			if spec.Type != "" {
+				WithFieldType(spec.Name, spec.Type)(o)
+			}
+
+			if len(spec.AllowedOps) > 0 {
+				if o.fieldOperators == nil {
+					o.fieldOperators = map[string]map[RelationalOperator]struct{}{}
+				}
+				ops := map[RelationalOperator]struct{}{}
+				for _, op := range spec.AllowedOps {
+					ops[op] = struct{}{}
+				}
+				o.fieldOperators[spec.Name] = ops
+			}
+
+			if len(spec.Enum) > 0 {
+				WithFieldEnum(spec.Name, spec.Enum...)(o)
+			}
+		}
+	}
+}
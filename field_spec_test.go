@@ -0,0 +1,31 @@
+package qparams
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWithFieldSpec(t *testing.T) {
+	t.Parallel()
+
+	var specs []FieldSpec
+	err := json.Unmarshal([]byte(`[
+		{"name":"status","filterable":true,"allowed_ops":["eq","in"],"enum":["active","inactive"]},
+		{"name":"created_at","sortable":true,"filterable":true},
+		{"name":"data","type":"jsonb","filterable":true}
+	]`), &specs)
+	assert.NilError(t, err)
+
+	opts := Options{allowedFilterFields: map[string]struct{}{"id": {}}}
+	WithFieldSpec(specs)(&opts)
+
+	assert.DeepEqual(t, opts.allowedFilterFields, map[string]struct{}{"status": {}, "created_at": {}, "data": {}})
+	assert.DeepEqual(t, opts.allowedOrderFields, map[string]struct{}{"created_at": {}})
+	assert.DeepEqual(t, opts.fieldTypes, map[string]FieldType{"data": TypeJSONB})
+	assert.DeepEqual(t, opts.fieldOperators, map[string]map[RelationalOperator]struct{}{
+		"status": {EqualsOperator: {}, InOperator: {}},
+	})
+	assert.DeepEqual(t, opts.fieldEnums, map[string]map[string]struct{}{"status": {"active": {}, "inactive": {}}})
+}
@@ -0,0 +1,62 @@
+package qparams
+
+// FieldType declares the type of a filterable field, enabling
+// type-specific validation and SQL rendering. Filters using an operator
+// meaningless for the type of their field, such as like on a number or
+// gt on a boolean, are rejected.
+type FieldType string
+
+const (
+	// TypeJSONB marks a Postgres JSONB column. Its sub-paths can be
+	// filtered with a dotted field name, e.g. "data.country".
+	TypeJSONB FieldType = "jsonb"
+
+	// TypeArray marks a Postgres array column. Only array fields can
+	// be filtered with ArrayContainsOperator.
+	TypeArray FieldType = "array"
+
+	// TypeText marks a text column. Every operator but array_contains
+	// applies to it.
+	TypeText FieldType = "text"
+
+	// TypeNumber marks a numeric column. The text operators, such as
+	// like or regex, do not apply to it.
+	TypeNumber FieldType = "number"
+
+	// TypeBool marks a boolean column. Its filter values must be one of
+	// the tokens configured with WithBoolTokens and are normalized to
+	// "true" or "false".
+	TypeBool FieldType = "bool"
+
+	// TypeTime marks a timestamp column. Its filter values can be
+	// relative time expressions such as "$now-7d" or "$today", resolved
+	// to RFC 3339 UTC timestamps before validation.
+	TypeTime FieldType = "time"
+)
+
+// String returns the name of t.
+func (t FieldType) String() string {
+	return string(t)
+}
+
+// allowsOperator reports whether op is meaningful on fields of type t.
+// Fields without a declared type allow every operator.
+func (t FieldType) allowsOperator(op RelationalOperator) bool {
+	textOnly := op == LikeOperator || op == ILikeOperator || op == IEqualsOperator ||
+		op == RegexOperator || op == IRegexOperator || op == FullTextOperator
+	ranged := op == GreaterThanOperator || op == GreaterThanEqualsOperator ||
+		op == LowerThanOperator || op == LowerThanEqualsOperator || op == BetweenOperator
+
+	switch t {
+	case TypeText:
+		return op != ArrayContainsOperator
+	case TypeNumber, TypeTime:
+		return !textOnly && op != ArrayContainsOperator
+	case TypeBool:
+		return !textOnly && !ranged && op != ArrayContainsOperator
+	case TypeArray:
+		return op == ArrayContainsOperator || op == IsNullOperator || op == IsNotNullOperator
+	default:
+		return true
+	}
+}
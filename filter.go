@@ -1,5 +1,14 @@
 package qparams
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
 // Filter represents a single filtering condition in a query.
 // It targets a specific field, applies a relational operator,
 // and compares against the given value.
@@ -7,6 +16,7 @@ package qparams
 // Example:
 //
 //	{ "field": "name", "op": "eq", "value": "Alice" }
+//	{ "field": "role", "op": "in", "value": ["admin", "editor"] }
 type Filter struct {
 	// Field is the name of the column or attribute being filtered.
 	Field string `json:"field"`
@@ -16,11 +26,263 @@ type Filter struct {
 
 	// Value is the comparison value used with the operator.
 	Value string `json:"value"`
+
+	// Values is the list of comparison values used with operators
+	// taking a list, such as in, nin, between and array_contains. In
+	// JSON it is sent as an array in the value property. When nil,
+	// Value is used as a single-element list.
+	Values []string `json:"-"`
+
+	// ValueField is the name of another field to compare against,
+	// in place of Value (e.g. updated_at gt created_at). It is
+	// mutually exclusive with Value.
+	ValueField string `json:"value_field,omitempty"`
+}
+
+// isEmpty reports whether g has neither filters nor nested groups.
+// An empty group matches everything.
+func (g FilterGroup) isEmpty() bool {
+	return len(g.Filters) == 0 && len(g.Groups) == 0
+}
+
+// String returns a compact, human-readable form of g, with its filters
+// and nested groups in parentheses joined by the logical operator, e.g.
+// (status eq active AND (role eq admin OR role eq editor)).
+func (g FilterGroup) String() string {
+	parts := make([]string, 0, len(g.Filters)+len(g.Groups))
+
+	for _, f := range g.Filters {
+		parts = append(parts, f.String())
+	}
+	for _, sub := range g.Groups {
+		parts = append(parts, sub.String())
+	}
+
+	return "(" + strings.Join(parts, " "+strings.ToUpper(g.Op.String())+" ") + ")"
+}
+
+// String returns a compact, human-readable form of f, e.g. "status eq
+// active", "role in [admin,editor]" or "updated_at gt field(created_at)"
+// for a comparison with another field.
+func (f Filter) String() string {
+	switch {
+	case f.isNullCheck():
+		return f.Field + " " + f.Op.String()
+	case f.ValueField != "":
+		return f.Field + " " + f.Op.String() + " field(" + f.ValueField + ")"
+	case f.takesList():
+		return f.Field + " " + f.Op.String() + " [" + strings.Join(f.values(), ",") + "]"
+	default:
+		return f.Field + " " + f.Op.String() + " " + f.Value
+	}
+}
+
+// alwaysFalse reports whether g obviously matches no row: an "and"
+// group with contradictory filters or a filter or group matching no row,
+// or an "or" group whose filters and groups all match no row.
+func (g FilterGroup) alwaysFalse() bool {
+	if g.isEmpty() {
+		return false
+	}
+
+	if g.Op == OrOperator {
+		for _, f := range g.Filters {
+			if _, ok := contradictoryField([]Filter{f}); !ok {
+				return false
+			}
+		}
+		for _, sub := range g.Groups {
+			if !sub.alwaysFalse() {
+				return false
+			}
+		}
+		return true
+	}
+
+	if _, ok := contradictoryField(g.Filters); ok {
+		return true
+	}
+
+	return slices.ContainsFunc(g.Groups, FilterGroup.alwaysFalse)
+}
+
+// alwaysTrue reports whether g obviously matches every row: an empty
+// group, an "and" group of such groups, or an "or" group with one.
+func (g FilterGroup) alwaysTrue() bool {
+	if g.isEmpty() {
+		return true
+	}
+
+	if g.Op == OrOperator {
+		return slices.ContainsFunc(g.Groups, FilterGroup.alwaysTrue)
+	}
+
+	if len(g.Filters) > 0 {
+		return false
+	}
+	for _, sub := range g.Groups {
+		if !sub.alwaysTrue() {
+			return false
+		}
+	}
+	return true
+}
+
+// clone returns a deep copy of g.
+func (g FilterGroup) clone() FilterGroup {
+	c := g
+	c.Filters = slices.Clone(g.Filters)
+	for i := range c.Filters {
+		c.Filters[i].Values = slices.Clone(c.Filters[i].Values)
+	}
+
+	if g.Groups != nil {
+		c.Groups = make([]FilterGroup, len(g.Groups))
+		for i := range g.Groups {
+			c.Groups[i] = g.Groups[i].clone()
+		}
+	}
+
+	return c
+}
+
+// jsonFilter is the JSON representation of a Filter, where value
+// holds either a single value or a list of values.
+type jsonFilter struct {
+	Field      string             `json:"field"`
+	Op         RelationalOperator `json:"op"`
+	Value      json.RawMessage    `json:"value"`
+	ValueField string             `json:"value_field,omitempty"`
+}
+
+// UnmarshalJSON decodes a filter, storing an array value in Values
+// and any other value in Value. Values can be strings, numbers or
+// booleans, numbers being stored as sent. A null value turns eq and ne
+// filters into isnull and notnull ones, as comparing with NULL never
+// matches in SQL. Unknown properties are rejected.
+func (f *Filter) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var aux jsonFilter
+	err := decoder.Decode(&aux)
+	if err != nil {
+		return err
+	}
+
+	*f = Filter{Field: aux.Field, Op: aux.Op, ValueField: aux.ValueField}
+
+	raw := bytes.TrimSpace(aux.Value)
+	if len(raw) == 0 {
+		return nil
+	}
+
+	if bytes.Equal(raw, []byte("null")) {
+		switch f.Op {
+		case EqualsOperator:
+			f.Op = IsNullOperator
+		case NotEqualsOperator:
+			f.Op = IsNotNullOperator
+		case IsNullOperator, IsNotNullOperator:
+		default:
+			return fmt.Errorf("null value not supported by relational operator %q, use %q or %q", f.Op, EqualsOperator, NotEqualsOperator)
+		}
+		return nil
+	}
+
+	if raw[0] == '[' {
+		var values []json.RawMessage
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return err
+		}
+
+		f.Values = make([]string, len(values))
+		for i, v := range values {
+			if f.Values[i], err = decodeFilterValue(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	f.Value, err = decodeFilterValue(raw)
+	return err
+}
+
+// decodeFilterValue decodes a JSON string, number or boolean. Numbers are kept
+// as their literal text, so that large integers such as 64-bit IDs do
+// not lose precision going through float64.
+func decodeFilterValue(raw json.RawMessage) (string, error) {
+	// numbers are kept as written, see ParseOptions
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return "", err
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("filter value must be a string, a number or a boolean, got %s", raw)
+	}
+}
+
+// MarshalJSON encodes a filter, writing Values as an array in the
+// value property when set.
+func (f Filter) MarshalJSON() ([]byte, error) {
+	var (
+		value []byte
+		err   error
+	)
+
+	if f.Values != nil {
+		value, err = json.Marshal(f.Values)
+	} else {
+		value, err = json.Marshal(f.Value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonFilter{Field: f.Field, Op: f.Op, Value: value, ValueField: f.ValueField})
+}
+
+// takesList reports whether the operator of the filter compares the
+// field against a list of values.
+func (f Filter) takesList() bool {
+	return f.Op == InOperator || f.Op == NotInOperator || f.Op == ArrayContainsOperator || f.Op == BetweenOperator
+}
+
+// isNullCheck reports whether the operator of the filter checks the
+// field for NULL, ignoring the comparison value.
+func (f Filter) isNullCheck() bool {
+	return f.Op == IsNullOperator || f.Op == IsNotNullOperator
+}
+
+// values returns the list of comparison values of the filter,
+// falling back to Value when Values is nil.
+func (f Filter) values() []string {
+	if f.Values != nil {
+		return f.Values
+	}
+
+	return []string{f.Value}
 }
 
 // FilterGroup represents a collection of filters combined together
 // with a logical operator (AND/OR). FilterGroups can be nested,
 // enabling the construction of complex, tree-like query conditions.
+// A group without filters and nested groups is valid, whatever its
+// operator, and matches everything.
 //
 // Example:
 //
@@ -36,3 +298,45 @@ type FilterGroup struct {
 	// Groups allows nesting of additional filter groups for more complex queries.
 	Groups []FilterGroup `json:"groups,omitempty"`
 }
+
+// walk calls visit for g with a nil filter, then for each filter of g,
+// then walks the nested groups of g in order.
+func (g *FilterGroup) walk(visit func(*FilterGroup, *Filter)) {
+	visit(g, nil)
+
+	for i := range g.Filters {
+		visit(g, &g.Filters[i])
+	}
+
+	for i := range g.Groups {
+		g.Groups[i].walk(visit)
+	}
+}
+
+// lowercaseColumn lowercases field up to its first dot.
+func lowercaseColumn(field string) string {
+	column, path, ok := strings.Cut(field, ".")
+	if !ok {
+		return strings.ToLower(field)
+	}
+	return strings.ToLower(column) + "." + path
+}
+
+// redact replaces the value of f with redactedValue when it filters
+// one of the given fields. Paths inside JSONB fields are redacted with
+// their column.
+func (f *Filter) redact(fields map[string]struct{}) {
+	column, _, _ := strings.Cut(f.Field, ".")
+	if _, ok := fields[column]; !ok {
+		if _, ok := fields[f.Field]; !ok {
+			return
+		}
+	}
+
+	if f.Value != "" {
+		f.Value = redactedValue
+	}
+	for j := range f.Values {
+		f.Values[j] = redactedValue
+	}
+}
@@ -0,0 +1,128 @@
+package qparams
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestFilterUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		data        string
+		expected    Filter
+		expectedErr string
+	}{
+		{
+			name:     "with single value",
+			data:     `{"field":"name","op":"eq","value":"alice"}`,
+			expected: Filter{Field: "name", Op: EqualsOperator, Value: "alice"},
+		},
+		{
+			name:     "with list of values",
+			data:     `{"field":"role","op":"in","value":["admin","editor"]}`,
+			expected: Filter{Field: "role", Op: InOperator, Values: []string{"admin", "editor"}},
+		},
+		{
+			name:     "with empty list of values",
+			data:     `{"field":"role","op":"in","value":[]}`,
+			expected: Filter{Field: "role", Op: InOperator, Values: []string{}},
+		},
+		{
+			name:     "with value field",
+			data:     `{"field":"updated_at","op":"gt","value_field":"created_at"}`,
+			expected: Filter{Field: "updated_at", Op: GreaterThanOperator, ValueField: "created_at"},
+		},
+		{
+			name:     "with large integer value",
+			data:     `{"field":"id","op":"eq","value":9007199254740993}`,
+			expected: Filter{Field: "id", Op: EqualsOperator, Value: "9007199254740993"},
+		},
+		{
+			name:     "with list of numbers",
+			data:     `{"field":"id","op":"in","value":[1,"2",3.5]}`,
+			expected: Filter{Field: "id", Op: InOperator, Values: []string{"1", "2", "3.5"}},
+		},
+		{
+			name:     "with null value",
+			data:     `{"field":"name","op":"isnull","value":null}`,
+			expected: Filter{Field: "name", Op: IsNullOperator},
+		},
+		{
+			name:     "with null value and eq",
+			data:     `{"field":"manager_id","op":"eq","value":null}`,
+			expected: Filter{Field: "manager_id", Op: IsNullOperator},
+		},
+		{
+			name:     "with null value and ne",
+			data:     `{"field":"manager_id","op":"ne","value":null}`,
+			expected: Filter{Field: "manager_id", Op: IsNotNullOperator},
+		},
+		{
+			name:        "with null value and gt",
+			data:        `{"field":"manager_id","op":"gt","value":null}`,
+			expectedErr: `null value not supported by relational operator "gt", use "eq" or "ne"`,
+		},
+		{
+			name:     "with boolean value",
+			data:     `{"field":"active","op":"eq","value":true}`,
+			expected: Filter{Field: "active", Op: EqualsOperator, Value: "true"},
+		},
+		{
+			name:        "with object value",
+			data:        `{"field":"active","op":"eq","value":{}}`,
+			expectedErr: "filter value must be a string, a number or a boolean, got {}",
+		},
+		{
+			name:        "with unknown property",
+			data:        `{"field":"name","op":"eq","value":"alice","foo":1}`,
+			expectedErr: `json: unknown field "foo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f Filter
+			err := json.Unmarshal([]byte(tt.data), &f)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, f, tt.expected)
+		})
+	}
+}
+
+func TestFilterMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected string
+	}{
+		{
+			name:     "with single value",
+			filter:   Filter{Field: "name", Op: EqualsOperator, Value: "alice"},
+			expected: `{"field":"name","op":"eq","value":"alice"}`,
+		},
+		{
+			name:     "with list of values",
+			filter:   Filter{Field: "role", Op: InOperator, Values: []string{"admin", "editor"}},
+			expected: `{"field":"role","op":"in","value":["admin","editor"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.filter)
+			assert.NilError(t, err)
+			assert.Equal(t, string(data), tt.expected)
+		})
+	}
+}
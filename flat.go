@@ -0,0 +1,204 @@
+package qparams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Reserved keys of the flat syntax, which cannot be used as filter
+// fields.
+const (
+	flatOrderBy = "order_by"
+	flatLimit   = "limit"
+	flatOffset  = "offset"
+	flatTerm    = "term"
+)
+
+// EncodeFlat renders s in the flat syntax, a human friendly alternative
+// to the JSON payload for simple searches, e.g.
+//
+//	status=eq:active&role=in:admin,editor&order_by=created_at:desc&limit=20
+//
+// Each filter is a field=op:value pair and filters are ANDed. Values of
+// list operators are comma separated, with commas and backslashes in
+// values escaped by a backslash. Order by clauses are field:direction
+// pairs in the order_by key.
+//
+// Only requests whose root group is an "and" group without nested
+// groups and value_field comparisons can be encoded, other requests
+// return an error. DecodeFlat is the inverse of EncodeFlat, up to the
+// order of filters on different fields.
+func (s *SearchRequest) EncodeFlat() (url.Values, error) {
+	if s.Having != nil || s.Cursor != nil || s.Distinct || s.IncludeDeleted {
+		return nil, errors.New("search request cannot be encoded in flat syntax")
+	}
+
+	values := url.Values{}
+
+	if s.Groups != nil && !s.Groups.isEmpty() {
+		if s.Groups.Op != AndOperator || len(s.Groups.Groups) > 0 {
+			return nil, errors.New("only and groups without nested groups can be encoded in flat syntax")
+		}
+
+		for _, f := range s.Groups.Filters {
+			if isFlatReserved(f.Field) {
+				return nil, fmt.Errorf("field %q is reserved in flat syntax", f.Field)
+			}
+
+			if f.ValueField != "" {
+				return nil, fmt.Errorf("filter on field %q: value_field cannot be encoded in flat syntax", f.Field)
+			}
+
+			v := escapeFlatValue(f.Value)
+			if f.takesList() {
+				escaped := make([]string, 0, len(f.values()))
+				for _, v := range f.values() {
+					escaped = append(escaped, escapeFlatValue(v))
+				}
+				v = strings.Join(escaped, ",")
+			}
+
+			values.Add(f.Field, f.Op.String()+":"+v)
+		}
+	}
+
+	for _, o := range s.OrderBy {
+		values.Add(flatOrderBy, o.Field+":"+o.Direction.String())
+	}
+
+	if s.Limit != nil {
+		values.Set(flatLimit, strconv.Itoa(*s.Limit))
+	}
+
+	if s.Offset != nil {
+		values.Set(flatOffset, strconv.Itoa(*s.Offset))
+	}
+
+	if s.Term != nil {
+		values.Set(flatTerm, *s.Term)
+	}
+
+	return values, nil
+}
+
+// DecodeFlat decodes a search request written in the flat syntax of
+// EncodeFlat. Filters are sorted by field. The returned request is not
+// validated: use ParseFlat to decode and validate it.
+func DecodeFlat(values url.Values) (*SearchRequest, error) {
+	var s SearchRequest
+
+	for _, field := range slices.Sorted(maps.Keys(values)) {
+		vs := values[field]
+
+		switch field {
+		case flatOrderBy:
+			for _, v := range vs {
+				// a field without direction sorts in ascending order,
+				// like a JSON clause without direction
+				f, d, _ := strings.Cut(v, ":")
+				s.OrderBy = append(s.OrderBy, OrderClause{Field: f, Direction: OrderDirection(d)})
+			}
+		case flatLimit, flatOffset:
+			n, err := strconv.Atoi(values.Get(field))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", field, err)
+			}
+			if field == flatLimit {
+				s.Limit = &n
+			} else {
+				s.Offset = &n
+			}
+		case flatTerm:
+			s.Term = ptr(values.Get(field))
+		default:
+			for _, v := range vs {
+				op, value, ok := strings.Cut(v, ":")
+				if !ok {
+					return nil, fmt.Errorf("invalid filter on field %q: expected op:value", field)
+				}
+
+				f := Filter{Field: field, Op: RelationalOperator(op)}
+				if f.takesList() {
+					f.Values = splitFlatValues(value)
+				} else {
+					f.Value = unescapeFlatValue(value)
+				}
+
+				if s.Groups == nil {
+					s.Groups = &FilterGroup{Op: AndOperator}
+				}
+				s.Groups.Filters = append(s.Groups.Filters, f)
+			}
+		}
+	}
+
+	return &s, nil
+}
+
+// ParseFlat decodes values with DecodeFlat, then validates and
+// normalizes the request like Parse. Errors are *RequestError values.
+func ParseFlat(values url.Values, opts *Options) (*SearchRequest, error) {
+	if opts == nil {
+		opts = NewOptions()
+	}
+
+	search, err := DecodeFlat(values)
+	if err != nil {
+		return nil, newRequestError(http.StatusBadRequest, err)
+	}
+
+	return processSearchRequest(context.Background(), search, opts, nil)
+}
+
+// isFlatReserved reports whether key is reserved in the flat syntax.
+func isFlatReserved(key string) bool {
+	switch key {
+	case flatOrderBy, flatLimit, flatOffset, flatTerm:
+		return true
+	default:
+		return false
+	}
+}
+
+// escapeFlatValue escapes backslashes and commas of v.
+func escapeFlatValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `,`, `\,`).Replace(v)
+}
+
+// unescapeFlatValue reverts escapeFlatValue.
+func unescapeFlatValue(v string) string {
+	values := splitFlatValues(v)
+	return strings.Join(values, ",")
+}
+
+// splitFlatValues splits v on unescaped commas and unescapes the
+// resulting values.
+func splitFlatValues(v string) []string {
+	values := []string{}
+
+	var sb strings.Builder
+	escaped := false
+	for _, r := range v {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			values = append(values, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return append(values, sb.String())
+}
@@ -0,0 +1,143 @@
+package qparams
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchRequestEncodeFlat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		search      SearchRequest
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "with filters, order and pagination",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "role", Op: InOperator, Values: []string{"admin", `a,b\c`}},
+						{Field: "status", Op: EqualsOperator, Value: "active"},
+					},
+				},
+				OrderBy: []OrderClause{{Field: "created_at", Direction: OrderDesc}, {Field: "id", Direction: OrderAsc}},
+				Limit:   ptr(20),
+				Offset:  ptr(40),
+				Term:    ptr("alice"),
+			},
+			expected: `limit=20&offset=40&order_by=created_at%3Adesc&order_by=id%3Aasc&role=in%3Aadmin%2Ca%5C%2Cb%5C%5Cc&status=eq%3Aactive&term=alice`,
+		},
+		{
+			name: "with or group",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: OrOperator, Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}}},
+			},
+			expectedErr: "only and groups without nested groups can be encoded in flat syntax",
+		},
+		{
+			name: "with reserved field",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "limit", Op: EqualsOperator, Value: "1"}}},
+			},
+			expectedErr: `field "limit" is reserved in flat syntax`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := tt.search.EncodeFlat()
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, values.Encode(), tt.expected)
+
+			decoded, err := DecodeFlat(values)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, decoded, &tt.search, cmpopts.IgnoreUnexported(SearchRequest{}))
+		})
+	}
+}
+
+func TestDecodeFlat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		query       string
+		expected    *SearchRequest
+		expectedErr string
+	}{
+		{
+			name:  "with escaped value",
+			query: `name=eq:a%5C,b`,
+			expected: &SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "name", Op: EqualsOperator, Value: "a,b"}}},
+			},
+		},
+		{
+			name:     "without filters",
+			query:    "limit=5",
+			expected: &SearchRequest{Limit: ptr(5)},
+		},
+		{
+			name:     "with order by without direction",
+			query:    "order_by=-created_at",
+			expected: &SearchRequest{OrderBy: []OrderClause{{Field: "-created_at"}}},
+		},
+		{
+			name:        "with missing operator",
+			query:       "name=foo",
+			expectedErr: `invalid filter on field "name": expected op:value`,
+		},
+		{
+			name:        "with invalid limit",
+			query:       "limit=ten",
+			expectedErr: "invalid limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			assert.NilError(t, err)
+
+			s, err := DecodeFlat(values)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, s, tt.expected, cmpopts.IgnoreUnexported(SearchRequest{}))
+		})
+	}
+}
+
+func TestParseFlat(t *testing.T) {
+	t.Parallel()
+
+	opts := NewOptions(WithFilterFields("status"), WithRelationalOperators(EqualsOperator))
+
+	_, err := ParseFlat(url.Values{"status": {"eq:active"}}, opts)
+	assert.NilError(t, err)
+
+	_, err = ParseFlat(url.Values{"name": {"eq:alice"}}, opts)
+	assert.Equal(t, StatusCode(err), http.StatusUnprocessableEntity)
+
+	opts = NewOptions(WithOrderFields("created_at"), WithDirectionAliases(map[string]OrderDirection{"-": OrderDesc}))
+
+	s, err := ParseFlat(url.Values{"order_by": {"-created_at"}}, opts)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s.OrderBy, []OrderClause{{Field: "created_at", Direction: OrderDesc}})
+}
@@ -0,0 +1,31 @@
+package qparams
+
+// fullTextQueryFuncs are the Postgres functions that can parse the value
+// of a full-text filter into a tsquery.
+var fullTextQueryFuncs = []string{"plainto_tsquery", "phraseto_tsquery", "websearch_to_tsquery", "to_tsquery"}
+
+// FullTextConfig configures how a field filtered with FullTextOperator
+// is matched in Postgres.
+type FullTextConfig struct {
+	// Language is the text search configuration, e.g. "english". When
+	// empty the default_text_search_config of the server is used.
+	Language string
+
+	// QueryFunc is the function parsing the filter value into a tsquery:
+	// plainto_tsquery (the default), phraseto_tsquery,
+	// websearch_to_tsquery or to_tsquery.
+	QueryFunc string
+}
+
+// WithFullTextField allows field to be filtered with FullTextOperator,
+// rendered as to_tsvector('english', body) @@ plainto_tsquery('english', ?)
+// for a field body configured with the english language. Full-text
+// filters are only supported by the Postgres dialect.
+func WithFullTextField(field string, config FullTextConfig) Option {
+	return func(o *Options) {
+		if o.fullTextFields == nil {
+			o.fullTextFields = map[string]FullTextConfig{}
+		}
+		o.fullTextFields[field] = config
+	}
+}
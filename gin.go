@@ -0,0 +1,54 @@
+//go:build gin
+
+package qparams
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ginSearchKey is the gin.Context key under which GinMiddleware stores
+// the parsed SearchRequest.
+const ginSearchKey = "qparams.search"
+
+// GinMiddleware creates the middleware of NewSearchHandler as a gin
+// HandlerFunc. The parsed request is stored in the gin.Context, where
+// GetSearchRequestGin retrieves it, and in the context of the HTTP
+// request. Errors are passed to the configured ErrorHandler with the
+// gin response writer and abort the handler chain.
+func GinMiddleware(opts ...Option) gin.HandlerFunc {
+	options := NewOptions(opts...)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		search, err := parseSearchRequest(c.Request, options)
+		if err != nil {
+			options.errorHandler(c.Writer, c.Request, err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(searchContext(c.Request.Context(), search, time.Since(start), options))
+
+		if search != nil {
+			setResponseHeaders(c.Writer.Header(), search, options)
+			c.Set(ginSearchKey, search)
+		}
+
+		c.Next()
+	}
+}
+
+// GetSearchRequestGin retrieves the SearchRequest stored in c by
+// GinMiddleware. If no request is stored, it returns nil.
+func GetSearchRequestGin(c *gin.Context) *SearchRequest {
+	v, ok := c.Get(ginSearchKey)
+	if !ok {
+		return nil
+	}
+
+	s, _ := v.(*SearchRequest)
+	return s
+}
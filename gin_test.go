@@ -0,0 +1,82 @@
+//go:build gin
+
+package qparams
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gotest.tools/v3/assert"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/users", GinMiddleware(
+		WithQueryParam("q"),
+		WithSearchMandatory(false),
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(EqualsOperator),
+		WithFilterFields("name"),
+		WithStoreRawQuery(true),
+		WithTimingInContext(true),
+	), func(c *gin.Context) {
+		_, ok := GetParseDuration(c.Request)
+		assert.Assert(t, ok)
+
+		search := GetSearchRequestGin(c)
+		if search == nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		assert.Equal(t, GetSearchRequest(c.Request), search)
+		assert.Equal(t, GetRawSearchQuery(c.Request), c.Query("q"))
+		c.String(http.StatusOK, search.Groups.Filters[0].Value)
+	})
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "with search",
+			query:          `{"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"alice"}]}}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "alice",
+		},
+		{
+			name:           "without search",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "with invalid search",
+			query:          `{"groups":{"op":"and","filters":[{"field":"email","op":"eq","value":"alice"}]}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   http.StatusText(http.StatusBadRequest),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/users"
+			if tt.query != "" {
+				target += "?q=" + url.QueryEscape(tt.query)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+			assert.Equal(t, rec.Code, tt.expectedStatus)
+			assert.Equal(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
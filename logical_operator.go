@@ -15,6 +15,12 @@ func (o LogicalOperator) Symbol() string {
 	}
 }
 
+// String returns the canonical value of the LogicalOperator as sent in
+// search payloads (e.g. "and"), unlike Symbol which returns its SQL form.
+func (o LogicalOperator) String() string {
+	return string(o)
+}
+
 const (
 	// AndOperator represents a logical AND between filters or groups.
 	AndOperator LogicalOperator = "and"
@@ -37,3 +37,10 @@ func TestLogicalOperatorSymbol(t *testing.T) {
 		})
 	}
 }
+
+func TestLogicalOperatorString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, LogicalOperator("foo").String(), "foo")
+	assert.Equal(t, OrOperator.String(), "or")
+}
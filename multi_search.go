@@ -0,0 +1,64 @@
+package qparams
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// namedSearchKey returns the context key under which the SearchRequest
+// parsed from the query parameter name is stored.
+func namedSearchKey(name string) contextKey {
+	return contextKey("search:" + name)
+}
+
+// NewMultiSearchHandler creates a middleware parsing several independent
+// search payloads from the same request, e.g. ?users={...}&orders={...}
+// for a dashboard filtering two datasets at once. Each key of searches
+// is the query parameter of a search, which is validated with its own
+// options and stored under its name; retrieve it with
+// GetSearchRequestFrom. A WithQueryParam in the options is ignored.
+//
+// Searches are parsed in name order and the first failure is reported
+// with the error handler of the failing search.
+func NewMultiSearchHandler(searches map[string][]Option) func(http.Handler) http.Handler {
+	names := slices.Sorted(maps.Keys(searches))
+
+	options := make([]*Options, len(names))
+	for i, name := range names {
+		options[i] = NewOptions(append(slices.Clone(searches[name]), WithQueryParam(name))...)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			for i, name := range names {
+				search, err := parseSearchRequest(r, options[i])
+				if err != nil {
+					options[i].errorHandler(w, r, err)
+					return
+				}
+
+				if search == nil {
+					continue
+				}
+
+				setResponseHeaders(w.Header(), search, options[i])
+
+				ctx = context.WithValue(ctx, namedSearchKey(name), search)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetSearchRequestFrom retrieves the SearchRequest parsed from the query
+// parameter name by NewMultiSearchHandler. If no request is stored, it
+// returns nil.
+func GetSearchRequestFrom(r *http.Request, name string) *SearchRequest {
+	s, _ := r.Context().Value(namedSearchKey(name)).(*SearchRequest)
+	return s
+}
@@ -0,0 +1,87 @@
+package qparams
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestNewMultiSearchHandler(t *testing.T) {
+	t.Parallel()
+
+	var users, orders *SearchRequest
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		users = GetSearchRequestFrom(r, "users")
+		orders = GetSearchRequestFrom(r, "orders")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := NewMultiSearchHandler(map[string][]Option{
+		"users": {
+			WithSearchMandatory(false),
+			WithLogicalOperators(AndOperator),
+			WithRelationalOperators(EqualsOperator),
+			WithFilterFields("name"),
+		},
+		"orders": {
+			WithSearchMandatory(false),
+			WithOrderFields("total"),
+			WithLimit(10),
+		},
+	})(next)
+
+	tests := []struct {
+		name           string
+		query          url.Values
+		expectedStatus int
+		expectedUsers  bool
+		expectedOrders bool
+	}{
+		{
+			name:           "without searches",
+			query:          url.Values{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "with both searches",
+			query: url.Values{
+				"users":  {`{"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"alice"}]}}`},
+				"orders": {`{"order_by":[{"field":"total","direction":"desc"}]}`},
+			},
+			expectedStatus: http.StatusOK,
+			expectedUsers:  true,
+			expectedOrders: true,
+		},
+		{
+			name:           "with one search",
+			query:          url.Values{"orders": {`{"limit":5}`}},
+			expectedStatus: http.StatusOK,
+			expectedOrders: true,
+		},
+		{
+			name: "with invalid search",
+			query: url.Values{
+				"users":  {`{}`},
+				"orders": {`{"order_by":[{"field":"name","direction":"desc"}]}`},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, orders = nil, nil
+
+			req := httptest.NewRequest(http.MethodGet, "/?"+tt.query.Encode(), nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, rr.Code, tt.expectedStatus)
+			assert.Equal(t, users != nil, tt.expectedUsers)
+			assert.Equal(t, orders != nil, tt.expectedOrders)
+		})
+	}
+}
@@ -15,6 +15,12 @@ func (d OrderDirection) Symbol() string {
 	}
 }
 
+// String returns the canonical value of the OrderDirection as sent in
+// search payloads (e.g. "asc"), unlike Symbol which returns its SQL form.
+func (d OrderDirection) String() string {
+	return string(d)
+}
+
 const (
 	// OrderAsc sorts results in ascending order (default).
 	OrderAsc OrderDirection = "asc"
@@ -36,3 +42,39 @@ type OrderClause struct {
 	// Direction is the order direction ("asc" or "desc").
 	Direction OrderDirection `json:"direction"`
 }
+
+// Asc returns an order by clause sorting field in ascending order.
+func Asc(field string) OrderClause {
+	return OrderClause{Field: field, Direction: OrderAsc}
+}
+
+// Desc returns an order by clause sorting field in descending order.
+func Desc(field string) OrderClause {
+	return OrderClause{Field: field, Direction: OrderDesc}
+}
+
+// OrderBy returns clauses as a list, to build order by clauses inline:
+//
+//	s := &qparams.SearchRequest{OrderBy: qparams.OrderBy(qparams.Desc("created_at"), qparams.Asc("id"))}
+func OrderBy(clauses ...OrderClause) []OrderClause {
+	return clauses
+}
+
+// SortField is an order by clause in the form taken by ORM ordering
+// APIs: a field and whether it is sorted in descending order.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// SortFields returns the order by clauses of s as SortField values.
+// Directions other than "desc" sort in ascending order, consistently
+// with OrderDirection.Symbol.
+func (s *SearchRequest) SortFields() []SortField {
+	fields := make([]SortField, len(s.OrderBy))
+	for i, o := range s.OrderBy {
+		fields[i] = SortField{Field: o.Field, Desc: o.Direction.Symbol() == string(OrderDesc)}
+	}
+
+	return fields
+}
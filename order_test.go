@@ -37,3 +37,39 @@ func TestOrderDirectionSymbol(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderDirectionString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, OrderDesc.String(), "desc")
+	assert.Equal(t, OrderDirection("foo").String(), "foo")
+}
+
+func TestSearchRequestSortFields(t *testing.T) {
+	t.Parallel()
+
+	s := SearchRequest{OrderBy: []OrderClause{
+		{Field: "created_at", Direction: OrderDesc},
+		{Field: "name", Direction: OrderAsc},
+		{Field: "id", Direction: OrderDirection("foo")},
+	}}
+
+	assert.DeepEqual(t, s.SortFields(), []SortField{
+		{Field: "created_at", Desc: true},
+		{Field: "name"},
+		{Field: "id"},
+	})
+	assert.DeepEqual(t, (&SearchRequest{}).SortFields(), []SortField{})
+}
+
+func TestOrderClauseConstructors(t *testing.T) {
+	t.Parallel()
+
+	assert.DeepEqual(t, Asc("id"), OrderClause{Field: "id", Direction: OrderAsc})
+	assert.DeepEqual(t, Desc("created_at"), OrderClause{Field: "created_at", Direction: OrderDesc})
+	assert.DeepEqual(t, OrderBy(Desc("created_at"), Asc("id")), []OrderClause{
+		{Field: "created_at", Direction: OrderDesc},
+		{Field: "id", Direction: OrderAsc},
+	})
+	assert.Assert(t, OrderBy() == nil)
+}
@@ -0,0 +1,180 @@
+package qparams
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// OverfetchLimit returns the limit to use in the query to detect whether
+// a next page exists without counting rows: one more than the limit of s.
+// The returned rows are then passed to TrimOverfetch. It returns false
+// when s has no limit. A limit of math.MaxInt is returned unchanged.
+func (s *SearchRequest) OverfetchLimit() (int, bool) {
+	if s.Limit == nil {
+		return 0, false
+	}
+
+	if *s.Limit == math.MaxInt {
+		return *s.Limit, true
+	}
+
+	return *s.Limit + 1, true
+}
+
+// TrimOverfetch trims the extra row fetched with the limit returned by
+// OverfetchLimit and reports whether a next page exists. Rows are
+// returned unchanged when s has no limit.
+//
+//	limit, _ := s.OverfetchLimit()
+//	rows, err := repo.Find(ctx, s, limit)
+//	...
+//	rows, hasNext := qparams.TrimOverfetch(s, rows)
+func TrimOverfetch[T any](s *SearchRequest, rows []T) ([]T, bool) {
+	if s.Limit == nil || len(rows) <= *s.Limit {
+		return rows, false
+	}
+
+	return rows[:*s.Limit], true
+}
+
+// NextPageSearch returns a copy of s requesting the next page, with the
+// offset advanced by the limit (a nil offset counts as 0) and clamped to
+// the maximum offset s was parsed with. It returns nil when s has no
+// limit, as a single page holds every result, when s is already at the
+// maximum offset, when the next offset exceeds the int range, or when s
+// uses a cursor: the cursor of the next page depends on the last row
+// returned, so it is set by the application.
+func NextPageSearch(s *SearchRequest) *SearchRequest {
+	if s.Limit == nil || s.Cursor != nil {
+		return nil
+	}
+
+	current := 0
+	if s.Offset != nil {
+		current = *s.Offset
+	}
+
+	if current > math.MaxInt-*s.Limit {
+		return nil
+	}
+	offset := current + *s.Limit
+
+	if s.options != nil && s.options.maxOffset != nil {
+		if current >= *s.options.maxOffset {
+			return nil
+		}
+		offset = min(offset, *s.options.maxOffset)
+	}
+
+	next := s.Clone()
+	next.Offset = &offset
+
+	return next
+}
+
+// WritePaginationLinks sets the Link header (RFC 8288) of w with the
+// first, prev, next and last pages of s among total results, e.g.
+//
+//	Link: </users?q=%7B%22limit%22%3A20%2C%22offset%22%3A20%7D>; rel="next", ...
+//
+// Links repeat the URL of r, changing only the limit and offset of the
+// search payload, or the query parameters set with
+// WithSeparatePaginationParams, so the filters of the client are kept
+// as sent. Prev and next are omitted on the first and last pages. No
+// header is set when s has no limit or uses cursor pagination.
+func WritePaginationLinks(w http.ResponseWriter, r *http.Request, s *SearchRequest, total int) {
+	if s.Limit == nil || *s.Limit <= 0 || s.Cursor != nil {
+		return
+	}
+
+	opts := s.options
+	if opts == nil {
+		opts = NewOptions()
+	}
+
+	limit := *s.Limit
+	offset := 0
+	if s.Offset != nil {
+		offset = *s.Offset
+	}
+
+	last := 0
+	if total > 0 {
+		last = (total - 1) / limit * limit
+	}
+
+	pages := []struct {
+		rel    string
+		offset int
+		ok     bool
+	}{
+		{"first", 0, true},
+		{"prev", max(offset-limit, 0), offset > 0},
+		{"next", offset + limit, offset < last},
+		{"last", last, true},
+	}
+
+	var links []string
+	for _, p := range pages {
+		if !p.ok {
+			continue
+		}
+
+		u, err := pageURL(r, opts, limit, p.offset)
+		if err != nil {
+			return
+		}
+		links = append(links, "<"+u+`>; rel="`+p.rel+`"`)
+	}
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// pageURL returns the URL of r with the limit and offset of its search
+// payload, parsed with opts, set to limit and offset.
+func pageURL(r *http.Request, opts *Options, limit, offset int) (string, error) {
+	query := r.URL.Query()
+
+	payload := map[string]json.RawMessage{}
+	if raw := query.Get(opts.queryParam); raw != "" {
+		if len(opts.keyAliases) > 0 {
+			var err error
+			if raw, err = renameAliasedKeys(raw, opts.keyAliases); err != nil {
+				return "", err
+			}
+		}
+		if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+			return "", err
+		}
+	}
+
+	for _, p := range []struct {
+		key, param string
+		value      int
+	}{
+		{"limit", opts.limitParam, limit},
+		{"offset", opts.offsetParam, offset},
+	} {
+		if _, ok := payload[p.key]; p.param != "" && !ok {
+			query.Set(p.param, strconv.Itoa(p.value))
+			continue
+		}
+		payload[p.key] = json.RawMessage(strconv.Itoa(p.value))
+	}
+
+	if len(payload) > 0 {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return "", err
+		}
+		query.Set(opts.queryParam, string(b))
+	}
+
+	u := *r.URL
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
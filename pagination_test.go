@@ -0,0 +1,216 @@
+package qparams
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestOverfetchLimit(t *testing.T) {
+	t.Parallel()
+
+	limit, ok := (&SearchRequest{Limit: ptr(10)}).OverfetchLimit()
+	assert.Equal(t, ok, true)
+	assert.Equal(t, limit, 11)
+
+	_, ok = (&SearchRequest{}).OverfetchLimit()
+	assert.Equal(t, ok, false)
+
+	limit, ok = (&SearchRequest{Limit: ptr(math.MaxInt)}).OverfetchLimit()
+	assert.Equal(t, ok, true)
+	assert.Equal(t, limit, math.MaxInt)
+}
+
+func TestTrimOverfetch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		search          SearchRequest
+		rows            []int
+		expected        []int
+		expectedHasNext bool
+	}{
+		{
+			name:            "with extra row",
+			search:          SearchRequest{Limit: ptr(2)},
+			rows:            []int{1, 2, 3},
+			expected:        []int{1, 2},
+			expectedHasNext: true,
+		},
+		{
+			name:     "with last page",
+			search:   SearchRequest{Limit: ptr(2)},
+			rows:     []int{1, 2},
+			expected: []int{1, 2},
+		},
+		{
+			name:     "without limit",
+			search:   SearchRequest{},
+			rows:     []int{1, 2, 3},
+			expected: []int{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, hasNext := TrimOverfetch(&tt.search, tt.rows)
+			assert.DeepEqual(t, rows, tt.expected)
+			assert.Equal(t, hasNext, tt.expectedHasNext)
+		})
+	}
+}
+
+func TestNextPageSearch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		search         SearchRequest
+		expectedOffset *int
+	}{
+		{
+			name:           "with limit and offset",
+			search:         SearchRequest{Limit: ptr(10), Offset: ptr(20)},
+			expectedOffset: ptr(30),
+		},
+		{
+			name:           "without offset",
+			search:         SearchRequest{Limit: ptr(10)},
+			expectedOffset: ptr(10),
+		},
+		{
+			name:   "without limit",
+			search: SearchRequest{Offset: ptr(10)},
+		},
+		{
+			name:           "within max offset",
+			search:         SearchRequest{Limit: ptr(10), Offset: ptr(40), options: &Options{maxOffset: ptr(50)}},
+			expectedOffset: ptr(50),
+		},
+		{
+			name:   "beyond int range",
+			search: SearchRequest{Limit: ptr(10), Offset: ptr(math.MaxInt - 5)},
+		},
+		{
+			name:           "clamped to max offset",
+			search:         SearchRequest{Limit: ptr(10), Offset: ptr(45), options: &Options{maxOffset: ptr(50)}},
+			expectedOffset: ptr(50),
+		},
+		{
+			name:   "at max offset",
+			search: SearchRequest{Limit: ptr(10), Offset: ptr(50), options: &Options{maxOffset: ptr(50)}},
+		},
+		{
+			name:   "with cursor",
+			search: SearchRequest{Limit: ptr(10), Cursor: ptr("abc")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := NextPageSearch(&tt.search)
+			if tt.expectedOffset == nil {
+				assert.Assert(t, next == nil)
+				return
+			}
+
+			assert.DeepEqual(t, next.Offset, tt.expectedOffset)
+			assert.DeepEqual(t, next.Limit, tt.search.Limit)
+			assert.Assert(t, next.Offset != tt.search.Offset)
+		})
+	}
+}
+
+func TestWritePaginationLinks(t *testing.T) {
+	t.Parallel()
+
+	link := func(rel string, values url.Values) string {
+		return "</users?" + values.Encode() + `>; rel="` + rel + `"`
+	}
+	page := func(limit, offset int) url.Values {
+		return url.Values{"q": {fmt.Sprintf(`{"groups":{"op":"and"},"limit":%d,"offset":%d}`, limit, offset)}}
+	}
+
+	tests := []struct {
+		name     string
+		query    url.Values
+		opts     []Option
+		total    int
+		expected string
+	}{
+		{
+			name:  "with middle page",
+			query: page(10, 10),
+			total: 35,
+			expected: strings.Join([]string{
+				link("first", page(10, 0)),
+				link("prev", page(10, 0)),
+				link("next", page(10, 20)),
+				link("last", page(10, 30)),
+			}, ", "),
+		},
+		{
+			name:  "with first page",
+			query: page(10, 0),
+			total: 20,
+			expected: strings.Join([]string{
+				link("first", page(10, 0)),
+				link("next", page(10, 10)),
+				link("last", page(10, 10)),
+			}, ", "),
+		},
+		{
+			name:  "with last page and no results",
+			query: page(10, 0),
+			total: 0,
+			expected: strings.Join([]string{
+				link("first", page(10, 0)),
+				link("last", page(10, 0)),
+			}, ", "),
+		},
+		{
+			name:  "with custom query param and separate pagination params",
+			query: url.Values{"s": {`{"term":"alice"}`}, "limit": {"5"}, "offset": {"5"}},
+			opts: []Option{
+				WithQueryParam("s"), WithSearchTermFields("name"), WithSeparatePaginationParams("limit", "offset"),
+			},
+			total: 12,
+			expected: strings.Join([]string{
+				link("first", url.Values{"s": {`{"term":"alice"}`}, "limit": {"5"}, "offset": {"0"}}),
+				link("prev", url.Values{"s": {`{"term":"alice"}`}, "limit": {"5"}, "offset": {"0"}}),
+				link("next", url.Values{"s": {`{"term":"alice"}`}, "limit": {"5"}, "offset": {"10"}}),
+				link("last", url.Values{"s": {`{"term":"alice"}`}, "limit": {"5"}, "offset": {"10"}}),
+			}, ", "),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				WritePaginationLinks(w, r, GetSearchRequest(r), tt.total)
+			})
+			handler := NewSearchHandler(append([]Option{WithQueryParam("q")}, tt.opts...)...)(next)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?"+tt.query.Encode(), nil))
+			assert.Equal(t, rec.Code, http.StatusOK)
+			assert.Equal(t, rec.Header().Get("Link"), tt.expected)
+		})
+	}
+}
+
+func TestWritePaginationLinksWithoutLimit(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	WritePaginationLinks(rec, httptest.NewRequest(http.MethodGet, "/users", nil), &SearchRequest{}, 10)
+
+	assert.Assert(t, rec.Header().Values("Link") == nil)
+}
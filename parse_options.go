@@ -0,0 +1,120 @@
+package qparams
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ParseOptions bundles the settings of the JSON decoding of search
+// payloads, set at once with WithParseOptions.
+//
+// Numbers are deliberately not configurable: filter values are strings,
+// and numeric values are always kept as written (like the decoder
+// UseNumber setting) rather than going through float64, so that large
+// integers and decimals reach the database without losing precision.
+type ParseOptions struct {
+	// AllowUnknownFields ignores unknown properties of the payload and of
+	// filter groups instead of rejecting them. Unknown properties of
+	// filters are always rejected, as a misspelled value would otherwise
+	// silently match more rows.
+	AllowUnknownFields bool
+
+	// MaxBytes rejects payloads longer than MaxBytes bytes, like
+	// WithMaxPayloadSize. Zero or negative means no limit.
+	MaxBytes int
+
+	// MaxDepth rejects payloads nesting objects and arrays deeper than
+	// MaxDepth levels. Zero or negative means no limit.
+	MaxDepth int
+
+	// RejectDuplicateKeys rejects payloads setting the same top-level
+	// key more than once, which the decoder would otherwise resolve by
+	// silently keeping the last value. Keys are compared
+	// case-insensitively, as the decoder matches them.
+	RejectDuplicateKeys bool
+}
+
+// WithParseOptions configures the decoding of search payloads. It
+// replaces the limit set with WithMaxPayloadSize.
+func WithParseOptions(p ParseOptions) Option {
+	return func(o *Options) {
+		o.parseOptions = p
+		if p.MaxBytes > 0 {
+			o.maxPayloadSize = ptr(p.MaxBytes)
+		} else {
+			o.maxPayloadSize = nil
+		}
+	}
+}
+
+// checkDepth returns an error when the JSON payload s nests objects and
+// arrays deeper than MaxDepth. Malformed payloads are left to the
+// decoder.
+func (p ParseOptions) checkDepth(s string) error {
+	if p.MaxDepth <= 0 {
+		return nil
+	}
+
+	depth := 0
+	inString, escaped := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+			if depth > p.MaxDepth {
+				return fmt.Errorf("search payload exceeds the maximum depth of %d", p.MaxDepth)
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// checkDuplicateKeys returns an error when the top-level object of the
+// JSON payload s sets a key more than once. Malformed payloads are left
+// to the decoder.
+func (p ParseOptions) checkDuplicateKeys(s string) error {
+	if !p.RejectDuplicateKeys {
+		return nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(s))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil
+	}
+
+	seen := map[string]struct{}{}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+
+		key, _ := t.(string)
+		if _, ok := seen[strings.ToLower(key)]; ok {
+			return fmt.Errorf("duplicate key %q in payload", key)
+		}
+		seen[strings.ToLower(key)] = struct{}{}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil
+		}
+	}
+
+	return nil
+}
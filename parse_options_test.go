@@ -0,0 +1,97 @@
+package qparams
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWithParseOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithParseOptions(ParseOptions{AllowUnknownFields: true, MaxBytes: 128, MaxDepth: 4})
+	f(&opts)
+
+	assert.DeepEqual(t, opts.parseOptions, ParseOptions{AllowUnknownFields: true, MaxBytes: 128, MaxDepth: 4})
+	assert.DeepEqual(t, opts.maxPayloadSize, ptr(128))
+
+	f = WithParseOptions(ParseOptions{})
+	f(&opts)
+
+	assert.Assert(t, opts.maxPayloadSize == nil)
+}
+
+func TestDecodeSearchRequestParseOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		raw         string
+		parse       ParseOptions
+		expectedErr string
+	}{
+		{
+			name:        "with unknown field",
+			raw:         `{"foo":1}`,
+			parse:       ParseOptions{},
+			expectedErr: `json: unknown field "foo"`,
+		},
+		{
+			name:  "with unknown field allowed",
+			raw:   `{"foo":1,"groups":{"op":"and","bar":true}}`,
+			parse: ParseOptions{AllowUnknownFields: true},
+		},
+		{
+			name:        "with unknown filter field allowed",
+			raw:         `{"groups":{"op":"and","filters":[{"field":"a","op":"eq","valeu":"x"}]}}`,
+			parse:       ParseOptions{AllowUnknownFields: true},
+			expectedErr: `json: unknown field "valeu"`,
+		},
+		{
+			name:  "within max depth",
+			raw:   `{"groups":{"op":"and","filters":[{"field":"a","op":"in","value":["{[{["]}]}}`,
+			parse: ParseOptions{MaxDepth: 5},
+		},
+		{
+			name:        "beyond max depth",
+			raw:         `{"groups":{"op":"and","groups":[{"op":"or"}]}}`,
+			parse:       ParseOptions{MaxDepth: 3},
+			expectedErr: "search payload exceeds the maximum depth of 3",
+		},
+		{
+			name:        "with duplicate key",
+			raw:         `{"limit":10,"offset":0,"limit":1000}`,
+			parse:       ParseOptions{RejectDuplicateKeys: true},
+			expectedErr: `duplicate key "limit" in payload`,
+		},
+		{
+			name:        "with duplicate key differing in case",
+			raw:         `{"limit":10,"LIMIT":1000}`,
+			parse:       ParseOptions{RejectDuplicateKeys: true},
+			expectedErr: `duplicate key "LIMIT" in payload`,
+		},
+		{
+			name:  "with duplicate nested key",
+			raw:   `{"groups":{"op":"and","op":"or"}}`,
+			parse: ParseOptions{RejectDuplicateKeys: true},
+		},
+		{
+			name:  "with duplicate key allowed",
+			raw:   `{"limit":10,"limit":1000}`,
+			parse: ParseOptions{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeSearchRequest(tt.raw, tt.parse, nil)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NilError(t, err)
+		})
+	}
+}
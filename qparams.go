@@ -5,15 +5,28 @@
 package qparams
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"math"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
+// defaultBoolTokens are the values accepted for TypeBool fields when no
+// tokens are set with WithBoolTokens.
+var defaultBoolTokens = map[string]bool{"true": true, "false": false}
+
 // contextKey is a custom type used to avoid collisions when
 // storing values in request contexts.
 type contextKey string
@@ -22,6 +35,18 @@ type contextKey string
 // objects are stored.
 const searchKey = contextKey("search")
 
+// parseDurationKey is the context key under which the parse duration
+// is stored when enabled with WithTimingInContext.
+const parseDurationKey = contextKey("parse_duration")
+
+// rawQueryKey is the context key under which the raw search payload is
+// stored when enabled with WithStoreRawQuery.
+const rawQueryKey = contextKey("raw_query")
+
+// pendingSearchKey is the context key under which ContextWithSearchRequest
+// stores a SearchRequest to be validated by NewValidationHandler.
+const pendingSearchKey = contextKey("pending_search")
+
 // ErrorHandler defines the signature of a function responsible
 // for handling request errors. It receives the HTTP response writer,
 // the request, and the encountered error.
@@ -38,11 +63,11 @@ var (
 
 	// defaultLogicalOperators defines the default set of logical
 	// operators allowed in filters.
-	defaultLogicalOperators map[LogicalOperator]struct{} = logicalOperators
+	defaultLogicalOperators map[LogicalOperator]struct{} = maps.Clone(logicalOperators)
 
 	// defaultRelationalOperators defines the default set of relational
 	// operators allowed in filters.
-	defaultRelationalOperators map[RelationalOperator]struct{} = relationalOperators
+	defaultRelationalOperators map[RelationalOperator]struct{} = withoutOperators(relationalOperators, optInOperators...)
 
 	// defaultLimit defines the default maximum limit applied to
 	// search requests. Nil means "no limit".
@@ -142,9 +167,155 @@ type Options struct {
 	allowedLogicalOperators    map[LogicalOperator]struct{}
 	allowedRelationalOperators map[RelationalOperator]struct{}
 	limit                      *int
+	fallbackLimit              *int
 	errorHandler               ErrorHandler
 	allowedFilterFields        map[string]struct{}
 	allowedOrderFields         map[string]struct{}
+	searchTermFields           []string
+	fieldTypes                 map[string]FieldType
+	isOffsetDisabled           bool
+	maxOrderFields             *int
+	maxPayloadSize             *int
+	valueTransformers          map[RelationalOperator][]func(string) string
+	costBudget                 *int
+	cost                       func(SearchStats) int
+	keyAliases                 map[string]string
+	fieldEnums                 map[string]map[string]struct{}
+	softDeleteColumn           string
+	isIncludeDeletedAllowed    bool
+	limitParam                 string
+	offsetParam                string
+	isDistinctAllowed          bool
+	redactedFields             map[string]struct{}
+	allowedHavingFields        map[string]struct{}
+	maxOffset                  *int
+	computedFields             map[string]string
+	deprecatedOperators        map[RelationalOperator]struct{}
+	isDeprecationHeaderEnabled bool
+	isPaginationHeadersEnabled bool
+	isRawQueryStored           bool
+	requiredFieldCombinations  [][]string
+	fieldOperators             map[string]map[RelationalOperator]struct{}
+	defaultOrderBy             []OrderClause
+	sortTiebreakers            []OrderClause
+	isContradictionCheck       bool
+	isCaseInsensitiveFields    bool
+	isFlatFiltersOnly          bool
+	boolTokens                 map[string]bool
+	valuePlaceholders          map[string]func(*http.Request) string
+	disabledOperators          map[RelationalOperator]string
+	isStrictValidation         bool
+	fullTextFields             map[string]FullTextConfig
+	parseOptions               ParseOptions
+	buildOptions               BuildOptions
+	searchExtractor            func(*http.Request) (*SearchRequest, error)
+	requestIDHeader            string
+	maxValueLength             *int
+	isSkipUnknownFields        bool
+	isFiltersRequired          bool
+	maxRegexLength             *int
+	directionAliases           map[string]OrderDirection
+	clock                      func() time.Time
+	isTimingInContext          bool
+	isBetweenOrderValidated    bool
+	orderFieldDirections       map[string]map[OrderDirection]struct{}
+	isCollectAllErrors         bool
+	fieldMap                   map[string]string
+	fieldNameTransform         func(string) string
+}
+
+// QueryParam returns the name of the query parameter carrying the
+// search payload.
+func (o *Options) QueryParam() string {
+	return o.queryParam
+}
+
+// AllowedFilterFields returns the sorted fields allowed in filters.
+func (o *Options) AllowedFilterFields() []string {
+	return slices.Sorted(maps.Keys(o.allowedFilterFields))
+}
+
+// AllowedOrderFields returns the sorted fields allowed in order by.
+func (o *Options) AllowedOrderFields() []string {
+	return slices.Sorted(maps.Keys(o.allowedOrderFields))
+}
+
+// AllowedLogicalOperators returns the sorted logical operators
+// allowed in filter groups.
+func (o *Options) AllowedLogicalOperators() []LogicalOperator {
+	return slices.Sorted(maps.Keys(o.allowedLogicalOperators))
+}
+
+// AllowedRelationalOperators returns the sorted relational operators
+// allowed in filters, excluding those disabled with
+// WithDisabledOperators.
+func (o *Options) AllowedRelationalOperators() []RelationalOperator {
+	return slices.DeleteFunc(slices.Sorted(maps.Keys(o.allowedRelationalOperators)), func(op RelationalOperator) bool {
+		_, disabled := o.disabledOperators[op]
+		return disabled
+	})
+}
+
+// Limit returns the maximum limit of search requests, and false when
+// there is none.
+func (o *Options) Limit() (int, bool) {
+	if o.limit == nil {
+		return 0, false
+	}
+
+	return *o.limit, true
+}
+
+// DefaultLimit returns the limit applied to search requests without
+// one, and false when there is none.
+func (o *Options) DefaultLimit() (int, bool) {
+	if o.fallbackLimit == nil {
+		return 0, false
+	}
+
+	return *o.fallbackLimit, true
+}
+
+// DefaultOrderBy returns the order by clauses applied to search
+// requests without one.
+func (o *Options) DefaultOrderBy() []OrderClause {
+	return slices.Clone(o.defaultOrderBy)
+}
+
+// SearchTermFields returns the fields matched by the search term.
+func (o *Options) SearchTermFields() []string {
+	return slices.Clone(o.searchTermFields)
+}
+
+// clone returns a copy of o whose sets can be modified, e.g. by applying
+// an Option, without affecting o.
+func (o *Options) clone() *Options {
+	c := *o
+	c.allowedLogicalOperators = maps.Clone(o.allowedLogicalOperators)
+	c.allowedRelationalOperators = maps.Clone(o.allowedRelationalOperators)
+	c.allowedFilterFields = maps.Clone(o.allowedFilterFields)
+	c.allowedOrderFields = maps.Clone(o.allowedOrderFields)
+	c.allowedHavingFields = maps.Clone(o.allowedHavingFields)
+	c.searchTermFields = slices.Clone(o.searchTermFields)
+	c.fieldTypes = maps.Clone(o.fieldTypes)
+	c.valueTransformers = maps.Clone(o.valueTransformers)
+	c.keyAliases = maps.Clone(o.keyAliases)
+	c.fieldEnums = maps.Clone(o.fieldEnums)
+	c.orderFieldDirections = maps.Clone(o.orderFieldDirections)
+	c.redactedFields = maps.Clone(o.redactedFields)
+	c.computedFields = maps.Clone(o.computedFields)
+	c.fieldMap = maps.Clone(o.fieldMap)
+	c.deprecatedOperators = maps.Clone(o.deprecatedOperators)
+	c.fieldOperators = maps.Clone(o.fieldOperators)
+	c.defaultOrderBy = slices.Clone(o.defaultOrderBy)
+	c.sortTiebreakers = slices.Clone(o.sortTiebreakers)
+	c.requiredFieldCombinations = slices.Clone(o.requiredFieldCombinations)
+	c.boolTokens = maps.Clone(o.boolTokens)
+	c.valuePlaceholders = maps.Clone(o.valuePlaceholders)
+	c.disabledOperators = maps.Clone(o.disabledOperators)
+	c.fullTextFields = maps.Clone(o.fullTextFields)
+
+	return &c
 }
 
 // Option is a functional option type used to configure Options
@@ -189,6 +360,54 @@ func WithRelationalOperators(values ...RelationalOperator) Option {
 	}
 }
 
+// WithStrictValidation configures whether the following additional
+// checks are enforced, rejecting requests that would otherwise be
+// accepted:
+//
+//   - field names, value fields included, must be identifiers, or
+//     dotted paths of identifiers for JSONB fields;
+//   - relational and logical operators must be defined by this package,
+//     even when allowed with WithRelationalOperators or
+//     WithLogicalOperators;
+//   - order directions must be "asc" or "desc", instead of falling back
+//     to "asc";
+//   - filter values must not be empty, except for isnull and notnull
+//     filters and filters comparing with a value field.
+func WithStrictValidation(value bool) Option {
+	return func(o *Options) {
+		o.isStrictValidation = value
+	}
+}
+
+// WithDisabledOperators rejects filters using the given relational
+// operators with the mapped reason as error message, e.g. "the 'in'
+// operator is disabled for performance reasons", instead of the generic
+// error of operators not allowed with WithRelationalOperators.
+func WithDisabledOperators(reasons map[RelationalOperator]string) Option {
+	return func(o *Options) {
+		o.disabledOperators = maps.Clone(reasons)
+	}
+}
+
+// WithTextOperators restricts the relational operators to those suited
+// for text fields: eq, ne, like, ilike and in.
+func WithTextOperators() Option {
+	return WithRelationalOperators(EqualsOperator, NotEqualsOperator, LikeOperator, ILikeOperator, InOperator)
+}
+
+// WithComparisonOperators restricts the relational operators to the
+// comparison ones: eq, ne, gt, gte, lt and lte.
+func WithComparisonOperators() Option {
+	return WithRelationalOperators(EqualsOperator, NotEqualsOperator,
+		GreaterThanOperator, GreaterThanEqualsOperator, LowerThanOperator, LowerThanEqualsOperator)
+}
+
+// WithAllOperators allows every relational operator supported by the
+// package, the regex ones included.
+func WithAllOperators() Option {
+	return WithRelationalOperators(slices.Collect(maps.Keys(relationalOperators))...)
+}
+
 // WithLimit sets a maximum number of results for search requests.
 // Negative values mean "no limit".
 func WithLimit(value int) Option {
@@ -201,6 +420,23 @@ func WithLimit(value int) Option {
 	}
 }
 
+// WithDefaultLimit sets the limit applied to search requests that
+// omit it. Negative values unset it.
+//
+// The limit of a request is resolved in this order: the limit sent
+// by the client, then the default limit, then the maximum limit set
+// with WithLimit. The resolved limit is then checked against the
+// maximum limit.
+func WithDefaultLimit(value int) Option {
+	return func(o *Options) {
+		if value < 0 {
+			o.fallbackLimit = nil
+		} else {
+			o.fallbackLimit = ptr(value)
+		}
+	}
+}
+
 // WithErrorHandler overrides the error handler used by the search handler.
 func WithErrorHandler(h ErrorHandler) Option {
 	return func(o *Options) {
@@ -242,7 +478,15 @@ func WithOrderFields(fields ...string) Option {
 	}
 }
 
-// WithOrderFields restricts the fields that can be used
+// WithSortableFields is WithOrderFields named after FieldSpec.Sortable:
+// it restricts the fields that can be used in order by clauses, e.g. to
+// the indexed ones, independently of the filter fields. Sorting on a
+// filterable field not listed is rejected with "field is not sortable".
+func WithSortableFields(fields ...string) Option {
+	return WithOrderFields(fields...)
+}
+
+// WithExtraOrderFields adds fields to the ones that can be used
 // in order by clauses.
 func WithExtraOrderFields(fields ...string) Option {
 	return func(o *Options) {
@@ -252,135 +496,1856 @@ func WithExtraOrderFields(fields ...string) Option {
 	}
 }
 
-// NewSearchHandler creates a middleware that parses, validates,
-// and injects a SearchRequest into the request context.
-// It can be customized via Option functions, falling back to
-// global defaults when not provided.
-func NewSearchHandler(opts ...Option) func(http.Handler) http.Handler {
-	options := &Options{
-		queryParam:                 defaultQueryParam,
-		isSearchMandatory:          defaultSearchMandatory,
-		allowedLogicalOperators:    defaultLogicalOperators,
-		allowedRelationalOperators: defaultRelationalOperators,
-		limit:                      defaultLimit,
-		errorHandler:               defaultErrorHandler,
-		allowedFilterFields:        defaultFilterFields,
-		allowedOrderFields:         defaultOrderFields,
+// WithFieldType declares the type of a filterable field. Fields
+// without a declared type are treated as plain columns.
+func WithFieldType(field string, t FieldType) Option {
+	return func(o *Options) {
+		if o.fieldTypes == nil {
+			o.fieldTypes = map[string]FieldType{}
+		}
+		o.fieldTypes[field] = t
 	}
+}
 
-	for _, opt := range opts {
-		opt(options)
+// WithSearchTermFields sets the fields matched by the free-text
+// search term. A request carrying a term is rejected when no
+// fields are configured.
+func WithSearchTermFields(fields ...string) Option {
+	return func(o *Options) {
+		o.searchTermFields = fields
 	}
+}
 
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			s := r.URL.Query().Get(options.queryParam)
-			if s == "" {
-				if !options.isSearchMandatory {
-					next.ServeHTTP(w, r)
-					return
-				}
+// WithOffsetDisabled configures whether the offset is rejected,
+// forcing clients to paginate with a cursor (keyset pagination).
+func WithOffsetDisabled(value bool) Option {
+	return func(o *Options) {
+		o.isOffsetDisabled = value
+	}
+}
 
-				options.errorHandler(w, r, fmt.Errorf("missing %q query parameter", options.queryParam))
-				return
-			}
+// WithComputedField declares a virtual filter field rendered by the SQL
+// builders as the server-defined expr, a predicate with a single ?
+// placeholder bound to the filter value, e.g.
+//
+//	WithComputedField("name_ci", "lower(name) = lower(?)")
+//
+// Computed fields are filterable with the eq operator only, as the
+// comparison is part of expr. They are not supported by ToElasticQuery
+// and the ent helpers.
+func WithComputedField(name, expr string) Option {
+	return func(o *Options) {
+		if o.computedFields == nil {
+			o.computedFields = map[string]string{}
+		}
+		o.computedFields[name] = expr
+	}
+}
 
-			decoder := json.NewDecoder(strings.NewReader(s))
-			decoder.DisallowUnknownFields()
+// WithFieldMap maps the field names used by clients to the column names
+// rendered by the SQL builders, e.g. {"createdAt": "created_at"}.
+// Validation and the other options use the client names. The column of
+// a JSONB path is mapped, its keys are not. Field maps are not applied
+// by ToElasticQuery and the ent helpers.
+func WithFieldMap(fields map[string]string) Option {
+	return func(o *Options) {
+		o.fieldMap = maps.Clone(fields)
+	}
+}
 
-			var search SearchRequest
-			if err := decoder.Decode(&search); err != nil {
-				options.errorHandler(w, r, err)
-				return
-			}
+// WithFieldNameTransform sets the function mapping the fields not in
+// the WithFieldMap to their column, e.g. from camelCase to snake_case,
+// so that only exceptions to a naming convention need to be listed.
+// Like field maps, it is applied by the SQL builders only.
+func WithFieldNameTransform(fn func(string) string) Option {
+	return func(o *Options) {
+		o.fieldNameTransform = fn
+	}
+}
 
-			if err := validateSearchRequest(&search, options); err != nil {
-				options.errorHandler(w, r, err)
-				return
-			}
+// WithDeprecatedOperators marks relational operators as deprecated:
+// requests using them are still accepted, but a warning is logged and,
+// with WithDeprecationHeader, the response carries a Deprecation
+// header.
+func WithDeprecatedOperators(ops ...RelationalOperator) Option {
+	return func(o *Options) {
+		o.deprecatedOperators = map[RelationalOperator]struct{}{}
+		for _, op := range ops {
+			o.deprecatedOperators[op] = struct{}{}
+		}
+	}
+}
 
-			ctx := context.WithValue(r.Context(), searchKey, &search)
+// WithRequestIDHeader sets the response header name to the CacheKey of
+// the normalized search request, so that clients and logs can correlate
+// a response with the exact query shape. An empty name disables it.
+func WithRequestIDHeader(name string) Option {
+	return func(o *Options) {
+		o.requestIDHeader = name
+	}
+}
 
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+// WithDeprecationHeader configures whether NewSearchHandler sets the
+// "Deprecation: true" response header on requests using a deprecated
+// operator.
+func WithDeprecationHeader(value bool) Option {
+	return func(o *Options) {
+		o.isDeprecationHeaderEnabled = value
 	}
 }
 
-func validateSearchRequest(s *SearchRequest, opts *Options) error {
-	// even though it is optional, if it is less than zero, it returns an error
-	if s.Limit != nil && *s.Limit < 0 {
-		return errors.New("limit must be null or >= 0")
+// WithPaginationHeaders configures whether NewSearchHandler sets the
+// "X-Limit" and "X-Offset" response headers to the limit and offset in
+// effect once the defaults are applied, so that clients know the page
+// they got. X-Offset is not set for cursor pagination.
+func WithPaginationHeaders(value bool) Option {
+	return func(o *Options) {
+		o.isPaginationHeadersEnabled = value
+	}
+}
+
+// WithMaxOffset sets the maximum offset of a search request, bounding
+// the cost of deep offset pagination. Negative values mean "no limit".
+func WithMaxOffset(value int) Option {
+	return func(o *Options) {
+		if value < 0 {
+			o.maxOffset = nil
+		} else {
+			o.maxOffset = ptr(value)
+		}
+	}
+}
+
+// WithDetectContradictions configures whether requests with obviously
+// contradictory filters on a field of an "and" group are rejected, such
+// as eq and ne on the same value, or in values all excluded by nin.
+func WithDetectContradictions(value bool) Option {
+	return func(o *Options) {
+		o.isContradictionCheck = value
+	}
+}
+
+// WithFlatFiltersOnly configures whether requests with nested filter
+// groups are rejected, for backends only supporting a single group of
+// filters combined with one logical operator.
+func WithFlatFiltersOnly(value bool) Option {
+	return func(o *Options) {
+		o.isFlatFiltersOnly = value
+	}
+}
+
+// WithDefaultOrderBy sets the order by clauses applied to requests
+// without order by. They are validated like the clauses sent by the
+// client, so their fields must be order fields.
+func WithDefaultOrderBy(clauses ...OrderClause) Option {
+	return func(o *Options) {
+		o.defaultOrderBy = slices.Clone(clauses)
+	}
+}
+
+// WithBoolTokens sets the values accepted for fields declared as
+// TypeBool, e.g. "1" and "yes" for clients not sending JSON booleans.
+// Tokens are matched case-insensitively. By default only "true" and
+// "false" are accepted.
+func WithBoolTokens(truthy, falsy []string) Option {
+	return func(o *Options) {
+		o.boolTokens = map[string]bool{}
+		for _, t := range truthy {
+			o.boolTokens[strings.ToLower(t)] = true
+		}
+		for _, t := range falsy {
+			o.boolTokens[strings.ToLower(t)] = false
+		}
+	}
+}
+
+// parseBool returns the boolean value of the token v, reporting whether
+// v is a valid token.
+func (o *Options) parseBool(v string) (bool, bool) {
+	tokens := o.boolTokens
+	if tokens == nil {
+		tokens = defaultBoolTokens
+	}
+
+	b, ok := tokens[strings.ToLower(v)]
+	return b, ok
+}
+
+// WithValuePlaceholders replaces filter values equal to a key of
+// placeholders (e.g. "$me") with the value returned by its function for
+// the current request, such as the id of the authenticated user, so that
+// clients can scope a search to themselves. Once set, values starting
+// with "$" that are not a placeholder are rejected, except the relative
+// time placeholders described in WithClock. Placeholders are resolved
+// by the middleware before validation, Parse and ParseFlat leave them
+// as is.
+func WithValuePlaceholders(placeholders map[string]func(*http.Request) string) Option {
+	return func(o *Options) {
+		o.valuePlaceholders = maps.Clone(placeholders)
+	}
+}
+
+// WithSortTiebreaker appends clauses to the order by of every search
+// request, e.g. a unique id making the order deterministic across pages.
+// Clauses on fields the request already orders by are skipped. Like soft
+// deletion, tiebreakers are added after validation, so their fields do
+// not need to be order fields and do not count toward the maximum
+// number of order fields.
+func WithSortTiebreaker(clauses ...OrderClause) Option {
+	return func(o *Options) {
+		o.sortTiebreakers = slices.Clone(clauses)
 	}
+}
 
-	if opts.limit != nil {
-		if s.Limit == nil {
-			return errors.New("limit is mandatory")
+// WithMaxOrderFields limits the number of order by clauses of a
+// search request. Negative values mean "no limit".
+func WithMaxOrderFields(value int) Option {
+	return func(o *Options) {
+		if value < 0 {
+			o.maxOrderFields = nil
+		} else {
+			o.maxOrderFields = ptr(value)
 		}
-		if *s.Limit > *opts.limit {
-			return fmt.Errorf("limit must be between 0 and %d", *opts.limit)
+	}
+}
+
+// WithDirectionAliases maps alternate spellings of order directions,
+// e.g. "ascending" or "descending", to the canonical ones before
+// validation. Aliases starting with a punctuation character, such as
+// "+" and "-", are also matched as a prefix of the field of clauses
+// without direction, e.g. {"field":"-created_at"} or
+// order_by=-created_at in the flat syntax (a "+" must be sent as %2B in
+// query strings). Once aliases are set, directions other than "asc" and
+// "desc" are rejected.
+func WithDirectionAliases(aliases map[string]OrderDirection) Option {
+	return func(o *Options) {
+		o.directionAliases = maps.Clone(aliases)
+	}
+}
+
+// WithTimingInContext configures whether NewSearchHandler stores the
+// time spent parsing and validating the search payload in the request
+// context, to be retrieved with GetParseDuration, e.g. by a logging
+// middleware.
+func WithTimingInContext(value bool) Option {
+	return func(o *Options) {
+		o.isTimingInContext = value
+	}
+}
+
+// WithStoreRawQuery configures whether NewSearchHandler stores the raw
+// search payload in the request context, to be retrieved with
+// GetRawSearchQuery, e.g. by an audit logging middleware.
+func WithStoreRawQuery(value bool) Option {
+	return func(o *Options) {
+		o.isRawQueryStored = value
+	}
+}
+
+// WithValidateBetweenOrder configures whether between filters whose
+// lower bound is greater than the upper bound, e.g. [100, 1], are
+// rejected instead of silently matching no rows. Bounds are compared as
+// numbers or RFC 3339 timestamps when both parse as such, other bounds
+// are not checked.
+func WithValidateBetweenOrder(value bool) Option {
+	return func(o *Options) {
+		o.isBetweenOrderValidated = value
+	}
+}
+
+// WithCollectAllErrors configures whether validation reports every
+// error of a search request, joined with errors.Join, instead of
+// stopping at the first one, so that clients can fix them at once. Use
+// FieldErrors to map them to their field. A field reports at most one
+// error per filter or order by clause.
+func WithCollectAllErrors(value bool) Option {
+	return func(o *Options) {
+		o.isCollectAllErrors = value
+	}
+}
+
+// WithRequireFilters configures whether requests without any filter are
+// rejected, forcing clients to narrow expensive searches. A non-empty
+// term counts as a filter, order by and pagination do not.
+func WithRequireFilters(value bool) Option {
+	return func(o *Options) {
+		o.isFiltersRequired = value
+	}
+}
+
+// WithRequiredFieldCombinations declares fields that must be filtered
+// together, e.g. the columns of a composite index: a filter on any field
+// of a combination requires filters on the others, in the same "and"
+// group or in an enclosing one. Otherwise the request is rejected. Each
+// call replaces the combinations set before.
+func WithRequiredFieldCombinations(combinations [][]string) Option {
+	return func(o *Options) {
+		o.requiredFieldCombinations = make([][]string, 0, len(combinations))
+		for _, c := range combinations {
+			o.requiredFieldCombinations = append(o.requiredFieldCombinations, slices.Clone(c))
 		}
 	}
+}
 
-	// even though it is optional, if it is less than zero, it returns an error
-	if s.Offset != nil && *s.Offset < 0 {
-		return errors.New("offset must be null or >= 0")
+// WithSkipUnknownFields configures whether filters and order by clauses
+// on fields that are not allowed are dropped instead of rejecting the
+// request, for lenient public APIs. The remaining filters still apply,
+// so dropping a filter broadens the search. Skipped fields are logged
+// and reported by SearchRequest.SkippedFields.
+func WithSkipUnknownFields(value bool) Option {
+	return func(o *Options) {
+		o.isSkipUnknownFields = value
 	}
+}
 
-	for _, o := range s.OrderBy {
-		if _, ok := opts.allowedOrderFields[o.Field]; !ok {
-			return fmt.Errorf("field %q not allowed in order by", o.Field)
+// WithMaxValueLength rejects filter values longer than value characters,
+// checking each value of filters taking a list. Negative values disable
+// the check.
+func WithMaxValueLength(value int) Option {
+	return func(o *Options) {
+		if value < 0 {
+			o.maxValueLength = nil
+		} else {
+			o.maxValueLength = ptr(value)
+		}
+	}
+}
+
+// WithMaxRegexLength rejects patterns of the regex operators longer
+// than value characters, bounding what a client can ask the database to
+// evaluate. Negative values disable the check.
+func WithMaxRegexLength(value int) Option {
+	return func(o *Options) {
+		if value < 0 {
+			o.maxRegexLength = nil
+		} else {
+			o.maxRegexLength = ptr(value)
 		}
 	}
+}
 
-	var validateGroup func(g *FilterGroup) error
-	validateGroup = func(g *FilterGroup) error {
-		if g == nil {
-			return nil
+// WithMaxPayloadSize rejects search payloads longer than value bytes
+// with ErrPayloadTooLarge. Negative values disable the check.
+func WithMaxPayloadSize(value int) Option {
+	return func(o *Options) {
+		if value < 0 {
+			o.maxPayloadSize = nil
+		} else {
+			o.maxPayloadSize = ptr(value)
 		}
+	}
+}
 
-		if _, ok := opts.allowedLogicalOperators[g.Op]; !ok {
-			return fmt.Errorf("logical operator %q not allowed", g.Op)
+// WithFieldEnum restricts the values a filter on field can compare
+// with to values. Every value of list filters must be in values.
+func WithFieldEnum(field string, values ...string) Option {
+	return func(o *Options) {
+		if o.fieldEnums == nil {
+			o.fieldEnums = map[string]map[string]struct{}{}
+		}
+		enum := map[string]struct{}{}
+		for _, v := range values {
+			enum[v] = struct{}{}
 		}
+		o.fieldEnums[field] = enum
+	}
+}
 
-		for _, f := range g.Filters {
-			if _, ok := opts.allowedFilterFields[f.Field]; !ok {
-				return fmt.Errorf("field %q not allowed in filters", f.Field)
-			}
+// WithOrderFieldDirections restricts the directions field can be
+// ordered by to dirs, e.g. ascending only for a field backed by an
+// ascending index. A clause without direction counts as ascending.
+func WithOrderFieldDirections(field string, dirs ...OrderDirection) Option {
+	return func(o *Options) {
+		if o.orderFieldDirections == nil {
+			o.orderFieldDirections = map[string]map[OrderDirection]struct{}{}
+		}
+		allowed := map[OrderDirection]struct{}{}
+		for _, d := range dirs {
+			allowed[d] = struct{}{}
+		}
+		o.orderFieldDirections[field] = allowed
+	}
+}
 
-			if _, ok := opts.allowedRelationalOperators[f.Op]; !ok {
-				return fmt.Errorf("relational operator %q not allowed for field %q", f.Op, f.Field)
-			}
+// WithSoftDeleteColumn excludes soft-deleted rows from every search by
+// ANDing an isnull filter on column to the root group. The filter is
+// added after validation, so column does not need to be filterable.
+func WithSoftDeleteColumn(column string) Option {
+	return func(o *Options) {
+		o.softDeleteColumn = column
+	}
+}
+
+// WithIncludeDeletedAllowed configures whether clients can set
+// include_deleted to skip the filter of WithSoftDeleteColumn.
+func WithIncludeDeletedAllowed(value bool) Option {
+	return func(o *Options) {
+		o.isIncludeDeletedAllowed = value
+	}
+}
+
+// WithAllowedHavingFields sets the fields allowed in the having filter
+// group, typically aliases of aggregates of GROUP BY queries.
+func WithAllowedHavingFields(fields ...string) Option {
+	return func(o *Options) {
+		o.allowedHavingFields = map[string]struct{}{}
+		for _, f := range fields {
+			o.allowedHavingFields[f] = struct{}{}
 		}
+	}
+}
 
-		for _, sg := range g.Groups {
-			if err := validateGroup(&sg); err != nil {
-				return err
-			}
+// WithRedactedFields sets the fields whose filter values are hidden
+// by (*SearchRequest).Redacted, e.g. to log requests filtering on
+// personal data.
+func WithRedactedFields(fields ...string) Option {
+	return func(o *Options) {
+		o.redactedFields = map[string]struct{}{}
+		for _, f := range fields {
+			o.redactedFields[f] = struct{}{}
 		}
+	}
+}
 
-		return nil
+// WithAllowDistinct configures whether clients can set distinct to
+// remove duplicate rows.
+func WithAllowDistinct(value bool) Option {
+	return func(o *Options) {
+		o.isDistinctAllowed = value
 	}
+}
 
-	if err := validateGroup(s.Groups); err != nil {
-		return err
+// WithCaseInsensitiveFields configures whether field names are matched
+// case-insensitively: the fields of filters, having and order by are
+// lowercased before validation, as are the allowed filter, order and
+// having fields. The fields of the other field-based options (e.g.
+// WithFieldType, WithFieldEnum or the per-field operators of
+// WithFieldSpec) are lowercased too. The column of a JSONB path is
+// lowercased, its keys are not.
+func WithCaseInsensitiveFields(value bool) Option {
+	return func(o *Options) {
+		o.isCaseInsensitiveFields = value
 	}
+}
 
-	return nil
+// WithSeparatePaginationParams reads the limit and offset from the
+// limitParam and offsetParam query parameters (e.g. ?q={...}&limit=20)
+// when the search payload omits them. Setting a value both in the
+// payload and in its query parameter is an error. An empty name
+// disables the corresponding parameter.
+func WithSeparatePaginationParams(limitParam, offsetParam string) Option {
+	return func(o *Options) {
+		o.limitParam = limitParam
+		o.offsetParam = offsetParam
+	}
 }
 
-// GetSearchRequest retrieves the parsed SearchRequest stored in the
-// request context by NewSearchHandler. If no request is stored, it
-// returns nil.
-func GetSearchRequest(r *http.Request) *SearchRequest {
-	v := r.Context().Value(searchKey)
-	if v == nil {
-		return nil
+// WithFieldAlias accepts alias as an alternate name of the top-level
+// payload key canonical (e.g. "sort" for "order_by"), to ease clients
+// migrating from other query libraries. The canonical key wins when a
+// payload sends both.
+func WithFieldAlias(alias, canonical string) Option {
+	return func(o *Options) {
+		if o.keyAliases == nil {
+			o.keyAliases = map[string]string{}
+		}
+		o.keyAliases[alias] = canonical
 	}
+}
 
-	s, ok := v.(*SearchRequest)
-	if !ok {
-		return nil
+// WithValueTransformer registers a function normalizing the values of
+// filters using op (e.g. trimming or lowercasing like patterns). It is
+// applied to every value of a filter once the request is validated,
+// before it is stored in the request context. Transformers registered
+// for the same operator run in registration order.
+func WithValueTransformer(op RelationalOperator, fn func(string) string) Option {
+	return func(o *Options) {
+		if o.valueTransformers == nil {
+			o.valueTransformers = map[RelationalOperator][]func(string) string{}
+		}
+		o.valueTransformers[op] = append(o.valueTransformers[op], fn)
 	}
+}
 
-	return s
+// WithCostBudget rejects search requests whose cost exceeds budget.
+// The cost is computed from the request SearchStats by the given
+// function or, when nil, as FilterCount + MaxDepth*2. Negative budgets
+// disable the check.
+func WithCostBudget(budget int, cost func(SearchStats) int) Option {
+	return func(o *Options) {
+		if budget < 0 {
+			o.costBudget = nil
+		} else {
+			o.costBudget = ptr(budget)
+		}
+
+		if cost == nil {
+			cost = defaultCost
+		}
+		o.cost = cost
+	}
+}
+
+// defaultCost is the cost function used by WithCostBudget when none
+// is provided.
+func defaultCost(s SearchStats) int {
+	return s.FilterCount + s.MaxDepth*2
+}
+
+// NewSearchHandler creates a middleware that parses, validates,
+// and injects a SearchRequest into the request context.
+// It can be customized via Option functions, falling back to
+// global defaults when not provided.
+func NewSearchHandler(opts ...Option) func(http.Handler) http.Handler {
+	options := NewOptions(opts...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			search, err := parseSearchRequest(r, options)
+			if err != nil {
+				options.errorHandler(w, r, err)
+				return
+			}
+
+			ctx := searchContext(r.Context(), search, time.Since(start), options)
+
+			if search != nil {
+				setResponseHeaders(w.Header(), search, options)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// searchContext returns a copy of ctx carrying the values stored by the
+// middlewares: the parsed search, which may be nil, its raw query and
+// the parse duration, as configured by options.
+func searchContext(ctx context.Context, search *SearchRequest, elapsed time.Duration, options *Options) context.Context {
+	if options.isTimingInContext {
+		ctx = context.WithValue(ctx, parseDurationKey, elapsed)
+	}
+
+	if search == nil {
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, searchKey, search)
+	if options.isRawQueryStored {
+		ctx = context.WithValue(ctx, rawQueryKey, search.raw)
+	}
+
+	return ctx
+}
+
+// setResponseHeaders sets the response headers describing the parsed
+// search request, as configured by options.
+func setResponseHeaders(h http.Header, search *SearchRequest, options *Options) {
+	if options.isDeprecationHeaderEnabled && len(search.deprecatedOperators) > 0 {
+		h.Set("Deprecation", "true")
+	}
+
+	if options.requestIDHeader != "" {
+		h.Set(options.requestIDHeader, search.CacheKey())
+	}
+
+	if options.isPaginationHeadersEnabled {
+		if search.Limit != nil {
+			h.Set("X-Limit", strconv.Itoa(*search.Limit))
+		}
+
+		if search.Cursor == nil {
+			offset := 0
+			if search.Offset != nil {
+				offset = *search.Offset
+			}
+			h.Set("X-Offset", strconv.Itoa(offset))
+		}
+	}
+}
+
+// NewOptions builds the Options of a handler starting from the global
+// defaults and applying opts in order. Default sets are copied so that
+// per-handler options never alter the global defaults.
+//
+// Handlers build their Options the same way, so NewOptions can be used
+// to inspect the rules enforced by a handler created with the same opts.
+//
+// NewOptions panics when no logical or relational operator is allowed,
+// e.g. after SetDefaultLogicalOperators is called without arguments, as
+// every filter would be rejected. Restrict the filter fields instead to
+// disable filtering.
+func NewOptions(opts ...Option) *Options {
+	options := &Options{
+		queryParam:                 defaultQueryParam,
+		isSearchMandatory:          defaultSearchMandatory,
+		allowedLogicalOperators:    maps.Clone(defaultLogicalOperators),
+		allowedRelationalOperators: maps.Clone(defaultRelationalOperators),
+		limit:                      defaultLimit,
+		errorHandler:               defaultErrorHandler,
+		allowedFilterFields:        maps.Clone(defaultFilterFields),
+		allowedOrderFields:         maps.Clone(defaultOrderFields),
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.isCaseInsensitiveFields {
+		lowercaseFieldOptions(options)
+	}
+
+	if len(options.allowedLogicalOperators) == 0 {
+		panic("qparams: at least one logical operator must be allowed")
+	}
+	if len(options.allowedRelationalOperators) == 0 {
+		panic("qparams: at least one relational operator must be allowed")
+	}
+
+	return options
+}
+
+// lowercaseFieldOptions lowercases the field names configured in o, so
+// that they match the fields lowercased by lowercaseFieldNames.
+func lowercaseFieldOptions(o *Options) {
+	for _, fields := range []*map[string]struct{}{
+		&o.allowedFilterFields, &o.allowedOrderFields, &o.allowedHavingFields, &o.redactedFields,
+	} {
+		*fields = lowercaseKeys(*fields)
+	}
+
+	o.fieldTypes = lowercaseKeys(o.fieldTypes)
+	o.fieldEnums = lowercaseKeys(o.fieldEnums)
+	o.fieldOperators = lowercaseKeys(o.fieldOperators)
+	o.computedFields = lowercaseKeys(o.computedFields)
+	o.orderFieldDirections = lowercaseKeys(o.orderFieldDirections)
+	o.fullTextFields = lowercaseKeys(o.fullTextFields)
+	o.fieldMap = lowercaseKeys(o.fieldMap)
+
+	if o.searchTermFields != nil {
+		o.searchTermFields = lowercaseFields(o.searchTermFields)
+	}
+
+	if o.requiredFieldCombinations != nil {
+		combos := make([][]string, len(o.requiredFieldCombinations))
+		for i, combo := range o.requiredFieldCombinations {
+			combos[i] = lowercaseFields(combo)
+		}
+		o.requiredFieldCombinations = combos
+	}
+}
+
+// lowercaseKeys returns a copy of m with the column of its keys
+// lowercased, or nil when m is nil.
+func lowercaseKeys[V any](m map[string]V) map[string]V {
+	if m == nil {
+		return nil
+	}
+
+	lowered := make(map[string]V, len(m))
+	for k, v := range m {
+		lowered[lowercaseColumn(k)] = v
+	}
+	return lowered
+}
+
+// lowercaseFields returns a copy of fields with their column lowercased.
+func lowercaseFields(fields []string) []string {
+	lowered := make([]string, len(fields))
+	for i, f := range fields {
+		lowered[i] = lowercaseColumn(f)
+	}
+	return lowered
+}
+
+// parseSearchRequest extracts, decodes and validates the search payload
+// of r. It returns a nil SearchRequest without error when the payload is
+// missing and not mandatory. Errors are wrapped in a RequestError.
+func parseSearchRequest(r *http.Request, options *Options) (*SearchRequest, error) {
+	query := r.URL.Query()
+
+	s := query.Get(options.queryParam)
+	if s == "" && hasPaginationParams(query, options) {
+		s = "{}"
+	}
+
+	if s == "" {
+		if !options.isSearchMandatory {
+			return nil, nil
+		}
+
+		return nil, newRequestError(http.StatusBadRequest, fmt.Errorf("missing %q query parameter", options.queryParam))
+	}
+
+	if options.maxPayloadSize != nil && len(s) > *options.maxPayloadSize {
+		return nil, newRequestError(http.StatusRequestEntityTooLarge, ErrPayloadTooLarge)
+	}
+
+	if err := r.Context().Err(); err != nil {
+		return nil, newRequestError(http.StatusServiceUnavailable, fmt.Errorf("search request aborted: %w", err))
+	}
+
+	search, err := decodeSearchRequest(s, options.parseOptions, options.keyAliases)
+	if err != nil {
+		// clients that do not URL-encode the payload may have it split
+		// on a '&' or altered by a '+': retry with the raw query segment
+		raw, ok := rawJSONQueryValue(r.URL.RawQuery, options.queryParam)
+		if !ok {
+			return nil, newRequestError(http.StatusBadRequest, err)
+		}
+
+		// the size checked above stopped at the first unescaped '&'
+		if options.maxPayloadSize != nil && len(raw) > *options.maxPayloadSize {
+			return nil, newRequestError(http.StatusRequestEntityTooLarge, ErrPayloadTooLarge)
+		}
+
+		search, err = decodeSearchRequest(raw, options.parseOptions, options.keyAliases)
+		if err != nil {
+			return nil, newRequestError(http.StatusBadRequest,
+				fmt.Errorf("search payload does not look URL-encoded, encode the %q query parameter: %w", options.queryParam, err))
+		}
+		s = raw
+	}
+
+	if query.Get(options.queryParam) != "" {
+		search.raw = s
+	}
+
+	if err := applyPaginationParams(search, query, options); err != nil {
+		return nil, newRequestError(http.StatusBadRequest, err)
+	}
+
+	return processSearchRequest(r.Context(), search, options, requestPlaceholder(r, options))
+}
+
+// requestPlaceholder returns the function resolving the value
+// placeholders of opts for r, passed to processSearchRequest.
+func requestPlaceholder(r *http.Request, opts *Options) func(string) string {
+	return func(name string) string {
+		return opts.valuePlaceholders[name](r)
+	}
+}
+
+// Parse decodes, validates and normalizes the raw JSON search payload
+// exactly like NewSearchHandler does with the query parameter, without
+// an HTTP request. As there is no request, the placeholders set with
+// WithValuePlaceholders are left as is. A nil opts uses the package
+// defaults. Errors are *RequestError values carrying the status the
+// middleware would use.
+func Parse(raw string, opts *Options) (*SearchRequest, error) {
+	if opts == nil {
+		opts = NewOptions()
+	}
+
+	if opts.maxPayloadSize != nil && len(raw) > *opts.maxPayloadSize {
+		return nil, newRequestError(http.StatusRequestEntityTooLarge, ErrPayloadTooLarge)
+	}
+
+	search, err := decodeSearchRequest(raw, opts.parseOptions, opts.keyAliases)
+	if err != nil {
+		return nil, newRequestError(http.StatusBadRequest, err)
+	}
+
+	return processSearchRequest(context.Background(), search, opts, nil)
+}
+
+// processSearchRequest runs the steps following the decoding of a search
+// request, shared by the middlewares, Parse and ParseFlat: it resolves
+// aliases and placeholders, applies the defaults, validates and
+// normalizes the request. placeholder returns the value of a placeholder
+// set with WithValuePlaceholders, nil leaving them as is. ctx is checked
+// for cancellation and passed to the logger.
+func processSearchRequest(
+	ctx context.Context, search *SearchRequest, opts *Options, placeholder func(string) string,
+) (*SearchRequest, error) {
+	resolveDirectionAliases(search, opts)
+
+	if opts.isCaseInsensitiveFields {
+		lowercaseFieldNames(search)
+	}
+
+	if opts.isSkipUnknownFields {
+		skipUnknownFields(search, opts)
+		for _, f := range search.skippedFields {
+			slog.Default().InfoContext(ctx, "unknown field skipped in search request", slog.String("field", f))
+		}
+	}
+
+	if err := resolvePlaceholders(search, opts, placeholder); err != nil {
+		return nil, newRequestError(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := resolveRelativeTimes(search, opts); err != nil {
+		return nil, newRequestError(http.StatusUnprocessableEntity, err)
+	}
+
+	applyDefaults(search, opts)
+
+	if err := validateSearchRequest(search, opts); err != nil {
+		return nil, newRequestError(http.StatusUnprocessableEntity, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, newRequestError(http.StatusServiceUnavailable, fmt.Errorf("search request aborted: %w", err))
+	}
+
+	// checked before normalization, so that server-side filters using a
+	// deprecated operator are not reported
+	search.deprecatedOperators = usedDeprecatedOperators(search, opts)
+	for _, op := range search.deprecatedOperators {
+		slog.Default().WarnContext(ctx, "deprecated relational operator used in search request",
+			slog.String("operator", op.String()))
+	}
+
+	normalizeSearchRequest(search, opts)
+
+	return search, nil
+}
+
+// compareBounds compares the bounds a and b of a between filter as
+// numbers or RFC 3339 timestamps, returning -1, 0 or +1. Bounds of
+// other or mixed types compare as equal.
+func compareBounds(a, b string) int {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			return cmp.Compare(x, y)
+		}
+		return 0
+	}
+
+	if x, err := time.Parse(time.RFC3339, a); err == nil {
+		if y, err := time.Parse(time.RFC3339, b); err == nil {
+			return x.Compare(y)
+		}
+	}
+
+	return 0
+}
+
+// resolveDirectionAliases replaces the aliased directions of the order
+// by clauses of s with the canonical ones configured in opts.
+func resolveDirectionAliases(s *SearchRequest, opts *Options) {
+	if len(opts.directionAliases) == 0 {
+		return
+	}
+
+	for i, o := range s.OrderBy {
+		if d, ok := opts.directionAliases[string(o.Direction)]; ok {
+			s.OrderBy[i].Direction = d
+			continue
+		}
+
+		if o.Direction != "" {
+			continue
+		}
+
+		for _, alias := range slices.Sorted(maps.Keys(opts.directionAliases)) {
+			d := opts.directionAliases[alias]
+			r, _ := utf8.DecodeRuneInString(alias)
+			if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+				if field, ok := strings.CutPrefix(o.Field, alias); ok {
+					s.OrderBy[i] = OrderClause{Field: field, Direction: d}
+					break
+				}
+			}
+		}
+	}
+}
+
+// lowercaseFieldNames lowercases the fields of the filters, having
+// filters and order by clauses of s.
+func lowercaseFieldNames(s *SearchRequest) {
+	// only the column of a JSONB path is lowercased, as JSON keys are
+	// case sensitive
+	s.Walk(func(_ *FilterGroup, f *Filter) {
+		if f != nil {
+			f.Field = lowercaseColumn(f.Field)
+			f.ValueField = lowercaseColumn(f.ValueField)
+		}
+	})
+
+	for i := range s.OrderBy {
+		s.OrderBy[i].Field = strings.ToLower(s.OrderBy[i].Field)
+	}
+}
+
+// hasPaginationParams reports whether query sets one of the separate
+// pagination parameters.
+func hasPaginationParams(query url.Values, opts *Options) bool {
+	return (opts.limitParam != "" && query.Has(opts.limitParam)) ||
+		(opts.offsetParam != "" && query.Has(opts.offsetParam))
+}
+
+// applyPaginationParams sets the limit and offset of s from the
+// separate pagination parameters of query.
+func applyPaginationParams(s *SearchRequest, query url.Values, opts *Options) error {
+	params := []struct {
+		name  string
+		value **int
+	}{
+		{opts.limitParam, &s.Limit},
+		{opts.offsetParam, &s.Offset},
+	}
+
+	for _, p := range params {
+		if p.name == "" || !query.Has(p.name) {
+			continue
+		}
+
+		if *p.value != nil {
+			return fmt.Errorf("%q set both in the search payload and as query parameter", p.name)
+		}
+
+		v, err := strconv.Atoi(query.Get(p.name))
+		if err != nil {
+			return fmt.Errorf("invalid %q query parameter: %w", p.name, err)
+		}
+		*p.value = &v
+	}
+
+	return nil
+}
+
+// decodeSearchRequest decodes a JSON search payload with the settings of
+// p, renaming aliased keys. Errors are *InvalidJSONError values.
+func decodeSearchRequest(s string, p ParseOptions, aliases map[string]string) (*SearchRequest, error) {
+	if err := p.checkDepth(s); err != nil {
+		return nil, &InvalidJSONError{Raw: s, Err: err}
+	}
+
+	if err := p.checkDuplicateKeys(s); err != nil {
+		return nil, &InvalidJSONError{Raw: s, Err: err}
+	}
+
+	payload := s
+
+	if len(aliases) > 0 {
+		var err error
+		if payload, err = renameAliasedKeys(s, aliases); err != nil {
+			return nil, &InvalidJSONError{Raw: s, Err: err}
+		}
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(payload))
+	if !p.AllowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	var search SearchRequest
+	if err := decoder.Decode(&search); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && (typeErr.Field == "limit" || typeErr.Field == "offset") &&
+			strings.HasPrefix(typeErr.Value, "number") {
+			err = fmt.Errorf("%s must be an integer", typeErr.Field)
+		}
+
+		return nil, &InvalidJSONError{Raw: s, Err: err}
+	}
+
+	return &search, nil
+}
+
+// renameAliasedKeys renames the top-level keys of the JSON object s
+// found in aliases to their canonical name. Aliased keys are dropped
+// when the canonical key is also set.
+func renameAliasedKeys(s string, aliases map[string]string) (string, error) {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(s), &payload); err != nil {
+		return "", err
+	}
+
+	for alias, canonical := range aliases {
+		v, ok := payload[alias]
+		if !ok {
+			continue
+		}
+
+		delete(payload, alias)
+		if _, ok := payload[canonical]; !ok {
+			payload[canonical] = v
+		}
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// rawJSONQueryValue looks in rawQuery for a value of param starting
+// with an unescaped '{' and returns it up to the matching '}', skipping
+// the JSON strings it contains. It reports false when param has no such
+// value, that is when the payload was correctly URL-encoded.
+func rawJSONQueryValue(rawQuery, param string) (string, bool) {
+	prefix := param + "="
+
+	start := -1
+	for i := 0; i < len(rawQuery); {
+		if strings.HasPrefix(rawQuery[i:], prefix) {
+			start = i + len(prefix)
+			break
+		}
+
+		next := strings.IndexByte(rawQuery[i:], '&')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+
+	if start < 0 || start >= len(rawQuery) || rawQuery[start] != '{' {
+		return "", false
+	}
+
+	depth, inString, escaped := 0, false, false
+	for i := start; i < len(rawQuery); i++ {
+		c := rawQuery[i]
+
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return rawQuery[start : i+1], true
+			}
+		}
+	}
+
+	return rawQuery[start:], true
+}
+
+// applyDefaults fills the values omitted by the client. A missing
+// limit falls back to the default limit, or to the maximum limit
+// when no default limit is configured, and a missing order by falls
+// back to the default order by.
+func applyDefaults(s *SearchRequest, opts *Options) {
+	if len(s.OrderBy) == 0 && len(opts.defaultOrderBy) > 0 {
+		s.OrderBy = appendOrderClauses(nil, opts.defaultOrderBy...)
+	}
+
+	if s.Limit == nil {
+		switch {
+		case opts.fallbackLimit != nil:
+			s.Limit = ptr(*opts.fallbackLimit)
+		case opts.limit != nil:
+			s.Limit = ptr(*opts.limit)
+		}
+	}
+}
+
+// validateSearchRequest checks s against opts. It returns the first
+// error found or, with WithCollectAllErrors, every error found joined
+// with errors.Join. Errors are ValidationError values.
+func validateSearchRequest(s *SearchRequest, opts *Options) error {
+	v := &validationErrors{isCollectAll: opts.isCollectAllErrors}
+	validateRequest(s, opts, v)
+
+	return v.err()
+}
+
+// validateRequest records in v the errors of s, stopping as soon as v
+// says so.
+func validateRequest(s *SearchRequest, opts *Options, v *validationErrors) {
+	// even though it is optional, if it is less than zero, it returns an error
+	if s.Limit != nil && *s.Limit < 0 {
+		if v.add("limit", errors.New("limit must be null or >= 0")) {
+			return
+		}
+	}
+
+	if opts.limit != nil && s.Limit != nil && *s.Limit > *opts.limit {
+		if v.add("limit", fmt.Errorf("limit must be between 0 and %d", *opts.limit)) {
+			return
+		}
+	}
+
+	if opts.isOffsetDisabled && s.Offset != nil {
+		if v.add("offset", errors.New("offset is not supported; use cursor")) {
+			return
+		}
+	}
+
+	// even though it is optional, if it is less than zero, it returns an error
+	if s.Offset != nil && *s.Offset < 0 {
+		if v.add("offset", errors.New("offset must be null or >= 0")) {
+			return
+		}
+	}
+
+	if s.Offset != nil && s.Cursor != nil {
+		if v.add("cursor", errors.New("cannot combine offset and cursor pagination")) {
+			return
+		}
+	}
+
+	if opts.maxOffset != nil && s.Offset != nil && *s.Offset > *opts.maxOffset {
+		if v.add("offset", fmt.Errorf("offset must be between 0 and %d", *opts.maxOffset)) {
+			return
+		}
+	}
+
+	// keeps offset + limit + 1, used by the pagination helpers, in the
+	// int range when WithLimit and WithMaxOffset do not bound them
+	if s.Limit != nil {
+		ceiling := math.MaxInt - 1
+		if s.Offset != nil && *s.Offset > 0 {
+			ceiling -= *s.Offset
+		}
+		if *s.Limit > ceiling {
+			if v.add("limit", errors.New("limit too large")) {
+				return
+			}
+		}
+	}
+
+	if s.Term != nil && *s.Term != "" && len(opts.searchTermFields) == 0 {
+		if v.add("term", errors.New("term search not allowed")) {
+			return
+		}
+	}
+
+	if s.IncludeDeleted && !opts.isIncludeDeletedAllowed {
+		if v.add("include_deleted", errors.New("include_deleted not allowed")) {
+			return
+		}
+	}
+
+	if s.Distinct && !opts.isDistinctAllowed {
+		if v.add("distinct", errors.New("distinct not allowed")) {
+			return
+		}
+	}
+
+	if opts.maxOrderFields != nil && len(s.OrderBy) > *opts.maxOrderFields {
+		if v.add("order_by", fmt.Errorf("too many order fields: %d > %d", len(s.OrderBy), *opts.maxOrderFields)) {
+			return
+		}
+	}
+
+	for i, o := range s.OrderBy {
+		if v.add(o.Field, validateOrderClause(s.OrderBy[:i], o, opts)) {
+			return
+		}
+	}
+
+	var validateGroup func(g *FilterGroup, clause string, isAllowed func(string) bool) bool
+	validateGroup = func(g *FilterGroup, clause string, isAllowed func(string) bool) bool {
+		// an empty group matches everything, its operator is irrelevant
+		if g == nil || g.isEmpty() {
+			return false
+		}
+
+		if opts.isStrictValidation {
+			if _, ok := logicalOperators[g.Op]; !ok {
+				if v.add("", fmt.Errorf("unknown logical operator %q", g.Op)) {
+					return true
+				}
+			}
+		}
+
+		if _, ok := opts.allowedLogicalOperators[g.Op]; !ok {
+			if v.add("", fmt.Errorf("logical operator %q not allowed", g.Op)) {
+				return true
+			}
+		}
+
+		if opts.isFlatFiltersOnly && len(g.Groups) > 0 {
+			if v.add("", errors.New("nested filter groups are not supported")) {
+				return true
+			}
+		}
+
+		for _, f := range g.Filters {
+			if v.add(f.Field, validateFilter(f, clause, isAllowed, opts)) {
+				return true
+			}
+		}
+
+		if opts.isContradictionCheck && g.Op == AndOperator {
+			if field, ok := contradictoryField(g.Filters); ok {
+				if v.add(field, fmt.Errorf("contradictory filters on field %q", field)) {
+					return true
+				}
+			}
+		}
+
+		for i := range g.Groups {
+			if validateGroup(&g.Groups[i], clause, isAllowed) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	isFilter := func(field string) bool { return isFilterField(field, opts) }
+	if validateGroup(s.Groups, "filters", isFilter) {
+		return
+	}
+
+	if len(opts.requiredFieldCombinations) > 0 && s.Groups != nil {
+		if field, err := validateFieldCombinations(s.Groups, opts.requiredFieldCombinations, nil); err != nil {
+			if v.add(field, err) {
+				return
+			}
+		}
+	}
+
+	isHavingField := func(field string) bool {
+		_, ok := opts.allowedHavingFields[field]
+		return ok
+	}
+	if validateGroup(s.Having, "having", isHavingField) {
+		return
+	}
+
+	if opts.isFiltersRequired && s.Stats().FilterCount == 0 && (s.Term == nil || *s.Term == "") {
+		if v.add("", errors.New("at least one filter is required")) {
+			return
+		}
+	}
+
+	if opts.costBudget != nil && opts.cost(s.Stats()) > *opts.costBudget {
+		v.add("", errors.New("query too expensive"))
+	}
+}
+
+// validateFieldCombinations checks that the filters of g, and of its
+// nested groups, on a field of one of combinations come with filters on
+// the other fields of the combination. The fields filtered by the "and"
+// groups enclosing g are in enclosing. It returns the offending field.
+func validateFieldCombinations(g *FilterGroup, combinations [][]string, enclosing map[string]struct{}) (string, error) {
+	filtered := maps.Clone(enclosing)
+	if g.Op == AndOperator {
+		if filtered == nil {
+			filtered = map[string]struct{}{}
+		}
+		for _, f := range g.Filters {
+			filtered[f.Field] = struct{}{}
+		}
+	}
+
+	for _, f := range g.Filters {
+		for _, c := range combinations {
+			if !slices.Contains(c, f.Field) {
+				continue
+			}
+
+			for _, other := range c {
+				if _, ok := filtered[other]; !ok && other != f.Field {
+					return f.Field, fmt.Errorf("field %q requires also filtering on %q", f.Field, other)
+				}
+			}
+		}
+	}
+
+	for i := range g.Groups {
+		if field, err := validateFieldCombinations(&g.Groups[i], combinations, filtered); err != nil {
+			return field, err
+		}
+	}
+
+	return "", nil
+}
+
+// validateOrderClause checks the order by clause o, following the
+// clauses in previous.
+func validateOrderClause(previous []OrderClause, o OrderClause, opts *Options) error {
+	if slices.ContainsFunc(previous, func(c OrderClause) bool { return c.Field == o.Field }) {
+		return fmt.Errorf("field %q ordered more than once", o.Field)
+	}
+
+	if opts.isStrictValidation {
+		if err := validateStrictField(o.Field); err != nil {
+			return err
+		}
+		if o.Direction != OrderAsc && o.Direction != OrderDesc {
+			return fmt.Errorf("invalid order direction %q for field %q", o.Direction, o.Field)
+		}
+	}
+
+	if len(opts.directionAliases) > 0 && o.Direction != "" && o.Direction != OrderAsc && o.Direction != OrderDesc {
+		return fmt.Errorf("invalid order direction %q for field %q", o.Direction, o.Field)
+	}
+
+	if _, ok := opts.allowedOrderFields[o.Field]; !ok {
+		if isFilterField(o.Field, opts) {
+			return fmt.Errorf("field %q is not sortable", o.Field)
+		}
+		return fmt.Errorf("field %q not allowed in order by", o.Field)
+	}
+
+	if dirs, ok := opts.orderFieldDirections[o.Field]; ok {
+		d := OrderDirection(o.Direction.Symbol())
+		if _, ok := dirs[d]; !ok {
+			return fmt.Errorf("direction %q not allowed for field %q", d, o.Field)
+		}
+	}
+
+	return nil
+}
+
+// validateFilter checks the filter f of a group of clause, whose fields
+// are allowed by isAllowed.
+func validateFilter(f Filter, clause string, isAllowed func(string) bool, opts *Options) error {
+	if !isAllowed(f.Field) {
+		if _, ok := opts.allowedOrderFields[f.Field]; ok && clause == "filters" {
+			return fmt.Errorf("field %q is not filterable", f.Field)
+		}
+		return fmt.Errorf("field %q not allowed in %s", f.Field, clause)
+	}
+
+	if opts.isStrictValidation {
+		if err := validateStrictFilter(f); err != nil {
+			return err
+		}
+	}
+
+	if opts.maxValueLength != nil {
+		for _, v := range f.values() {
+			if utf8.RuneCountInString(v) > *opts.maxValueLength {
+				return fmt.Errorf("value too long for field %q", f.Field)
+			}
+		}
+	}
+
+	if reason, ok := opts.disabledOperators[f.Op]; ok {
+		return errors.New(reason)
+	}
+
+	if _, ok := opts.allowedRelationalOperators[f.Op]; !ok {
+		return fmt.Errorf("relational operator %q not allowed for field %q", f.Op, f.Field)
+	}
+
+	column, _, _ := strings.Cut(f.Field, ".")
+	if ops, ok := opts.fieldOperators[column]; ok {
+		if _, ok := ops[f.Op]; !ok {
+			return fmt.Errorf("relational operator %q not allowed for field %q", f.Op, f.Field)
+		}
+	}
+
+	if _, ok := opts.computedFields[f.Field]; ok && (f.Op != EqualsOperator || f.ValueField != "") {
+		return fmt.Errorf("computed field %q only supports %q with a value", f.Field, EqualsOperator)
+	}
+
+	if f.Values != nil && !f.takesList() {
+		return fmt.Errorf("relational operator %q does not accept a list of values", f.Op)
+	}
+
+	if f.takesList() && len(f.values()) == 0 {
+		return fmt.Errorf("%q filter requires at least one value", f.Op)
+	}
+
+	if f.Op == BetweenOperator && f.ValueField == "" {
+		values := f.values()
+		if len(values) != 2 {
+			return fmt.Errorf("%q filter requires exactly two values", f.Op)
+		}
+
+		if opts.isBetweenOrderValidated && compareBounds(values[0], values[1]) > 0 {
+			return fmt.Errorf("between bounds are out of order for field %q", f.Field)
+		}
+	}
+
+	if f.Op == ArrayContainsOperator && opts.fieldTypes[f.Field] != TypeArray {
+		return fmt.Errorf("relational operator %q requires an array field, %q is not", f.Op, f.Field)
+	}
+
+	if t := opts.fieldTypes[f.Field]; !t.allowsOperator(f.Op) {
+		return fmt.Errorf("operator %q incompatible with field type %s", f.Op, t)
+	}
+
+	isRegex := f.Op == RegexOperator || f.Op == IRegexOperator
+	if isRegex && opts.maxRegexLength != nil && utf8.RuneCountInString(f.Value) > *opts.maxRegexLength {
+		return fmt.Errorf("regex pattern too long for field %q", f.Field)
+	}
+
+	if _, ok := opts.fullTextFields[f.Field]; f.Op == FullTextOperator && !ok {
+		return fmt.Errorf("relational operator %q requires a full-text field, %q is not", f.Op, f.Field)
+	}
+
+	if f.ValueField != "" {
+		if err := validateValueField(f, clause, isAllowed); err != nil {
+			return err
+		}
+	} else if enum, ok := opts.fieldEnums[f.Field]; ok && !f.isNullCheck() {
+		for _, v := range f.values() {
+			if _, ok := enum[v]; !ok {
+				return fmt.Errorf("value %q not allowed for field %q", v, f.Field)
+			}
+		}
+	}
+
+	if f.ValueField == "" && !f.isNullCheck() && opts.fieldTypes[f.Field] == TypeBool {
+		for _, v := range f.values() {
+			if _, ok := opts.parseBool(v); !ok {
+				return fmt.Errorf("value %q is not a valid boolean", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// skipUnknownFields removes from s the filters, having filters and order
+// by clauses on fields that are not allowed, recording them in
+// s.skippedFields.
+func skipUnknownFields(s *SearchRequest, opts *Options) {
+	skip := func(field string) {
+		if !slices.Contains(s.skippedFields, field) {
+			s.skippedFields = append(s.skippedFields, field)
+		}
+	}
+
+	var pruneGroup func(g *FilterGroup, isAllowed func(string) bool)
+	pruneGroup = func(g *FilterGroup, isAllowed func(string) bool) {
+		g.Filters = slices.DeleteFunc(g.Filters, func(f Filter) bool {
+			for _, field := range []string{f.Field, f.ValueField} {
+				if field != "" && !isAllowed(field) {
+					skip(field)
+					return true
+				}
+			}
+			return false
+		})
+
+		for i := range g.Groups {
+			pruneGroup(&g.Groups[i], isAllowed)
+		}
+	}
+
+	if s.Groups != nil {
+		pruneGroup(s.Groups, func(field string) bool { return isFilterField(field, opts) })
+	}
+	if s.Having != nil {
+		pruneGroup(s.Having, func(field string) bool {
+			_, ok := opts.allowedHavingFields[field]
+			return ok
+		})
+	}
+
+	s.OrderBy = slices.DeleteFunc(s.OrderBy, func(o OrderClause) bool {
+		if _, ok := opts.allowedOrderFields[o.Field]; !ok {
+			skip(o.Field)
+			return true
+		}
+		return false
+	})
+}
+
+// resolvePlaceholders replaces the filter values of s matching a value
+// placeholder with the value returned by placeholder, or leaves them as
+// is when placeholder is nil. It fails on values starting with "$" that
+// are neither a placeholder nor a relative time.
+func resolvePlaceholders(s *SearchRequest, opts *Options, placeholder func(string) string) error {
+	if opts.valuePlaceholders == nil {
+		return nil
+	}
+
+	now := opts.now()
+
+	resolve := func(v string) (string, error) {
+		if !strings.HasPrefix(v, "$") {
+			return v, nil
+		}
+
+		if _, ok := opts.valuePlaceholders[v]; ok {
+			if placeholder == nil {
+				return v, nil
+			}
+			return placeholder(v), nil
+		}
+
+		// relative times are resolved on TypeTime fields only, by
+		// resolveRelativeTimes
+		if _, ok, _ := resolveRelativeTime(v, now); ok {
+			return v, nil
+		}
+
+		return "", fmt.Errorf("unknown value placeholder %q", v)
+	}
+
+	var err error
+	s.Walk(func(_ *FilterGroup, f *Filter) {
+		if f == nil || err != nil {
+			return
+		}
+
+		if f.Value, err = resolve(f.Value); err != nil {
+			return
+		}
+		for j := range f.Values {
+			if f.Values[j], err = resolve(f.Values[j]); err != nil {
+				return
+			}
+		}
+	})
+
+	return err
+}
+
+// coerceBoolValues replaces the values of filters on TypeBool fields
+// with "true" or "false", once validated.
+func coerceBoolValues(s *SearchRequest, opts *Options) {
+	if !slices.Contains(slices.Collect(maps.Values(opts.fieldTypes)), TypeBool) {
+		return
+	}
+
+	s.Walk(func(_ *FilterGroup, f *Filter) {
+		if f == nil || f.ValueField != "" || f.isNullCheck() || opts.fieldTypes[f.Field] != TypeBool {
+			return
+		}
+
+		if f.Values == nil {
+			b, _ := opts.parseBool(f.Value)
+			f.Value = strconv.FormatBool(b)
+		}
+		for j := range f.Values {
+			b, _ := opts.parseBool(f.Values[j])
+			f.Values[j] = strconv.FormatBool(b)
+		}
+	})
+}
+
+// transformValues applies the configured value transformers to the
+// values of every filter of s, having filters included.
+func transformValues(s *SearchRequest, opts *Options) {
+	if len(opts.valueTransformers) == 0 {
+		return
+	}
+
+	s.Walk(func(_ *FilterGroup, f *Filter) {
+		if f == nil {
+			return
+		}
+
+		for _, fn := range opts.valueTransformers[f.Op] {
+			f.Value = fn(f.Value)
+			for j := range f.Values {
+				f.Values[j] = fn(f.Values[j])
+			}
+		}
+	})
+}
+
+// usedDeprecatedOperators returns the sorted deprecated operators used
+// by the filters of s.
+func usedDeprecatedOperators(s *SearchRequest, opts *Options) []RelationalOperator {
+	if len(opts.deprecatedOperators) == 0 {
+		return nil
+	}
+
+	used := map[RelationalOperator]struct{}{}
+
+	s.Walk(func(_ *FilterGroup, f *Filter) {
+		if f == nil {
+			return
+		}
+
+		if _, ok := opts.deprecatedOperators[f.Op]; ok {
+			used[f.Op] = struct{}{}
+		}
+	})
+
+	return slices.Sorted(maps.Keys(used))
+}
+
+// normalizeSearchRequest applies the server-side transformations to
+// the validated search request s and binds it to opts.
+func normalizeSearchRequest(s *SearchRequest, opts *Options) {
+	s.options = opts
+	coerceBoolValues(s, opts)
+	transformValues(s, opts)
+	expandSearchTerm(s, opts)
+	excludeSoftDeleted(s, opts)
+	s.OrderBy = appendOrderClauses(s.OrderBy, opts.sortTiebreakers...)
+}
+
+// appendOrderClauses appends to orderBy the clauses on fields it does
+// not order by yet, so that injecting clauses is idempotent.
+func appendOrderClauses(orderBy []OrderClause, clauses ...OrderClause) []OrderClause {
+	for _, c := range clauses {
+		if !slices.ContainsFunc(orderBy, func(o OrderClause) bool { return o.Field == c.Field }) {
+			orderBy = append(orderBy, c)
+		}
+	}
+
+	return orderBy
+}
+
+// excludeSoftDeleted ANDs an isnull filter on the soft delete column
+// to the root group of s, unless s includes deleted rows.
+func excludeSoftDeleted(s *SearchRequest, opts *Options) {
+	if opts.softDeleteColumn == "" || s.IncludeDeleted {
+		return
+	}
+
+	g := FilterGroup{
+		Op:      AndOperator,
+		Filters: []Filter{{Field: opts.softDeleteColumn, Op: IsNullOperator}},
+	}
+
+	if s.Groups == nil || s.Groups.isEmpty() {
+		s.Groups = &g
+		return
+	}
+
+	s.Groups = &FilterGroup{
+		Op:     AndOperator,
+		Groups: []FilterGroup{*s.Groups, g},
+	}
+}
+
+// expandSearchTerm turns the free-text term of s into an OR group of
+// ilike filters over the configured term fields and ANDs it with the
+// existing root group.
+func expandSearchTerm(s *SearchRequest, opts *Options) {
+	if s.Term == nil || *s.Term == "" || len(opts.searchTermFields) == 0 {
+		return
+	}
+
+	value := "%" + escapeLike(*s.Term) + "%"
+
+	term := FilterGroup{Op: OrOperator}
+	for _, f := range opts.searchTermFields {
+		term.Filters = append(term.Filters, Filter{Field: f, Op: ILikeOperator, Value: value})
+	}
+
+	if s.Groups == nil {
+		s.Groups = &term
+		return
+	}
+
+	s.Groups = &FilterGroup{
+		Op:     AndOperator,
+		Groups: []FilterGroup{*s.Groups, term},
+	}
+}
+
+// contradictoryField returns the first field whose eq, ne, in and nin
+// filters, ANDed together, cannot match any value.
+func contradictoryField(filters []Filter) (string, bool) {
+	type constraint struct {
+		allowed  map[string]struct{} // nil when unconstrained
+		excluded map[string]struct{}
+	}
+
+	constraints := map[string]*constraint{}
+	var fields []string
+
+	for _, f := range filters {
+		if f.ValueField != "" {
+			continue
+		}
+
+		c, ok := constraints[f.Field]
+		if !ok {
+			c = &constraint{excluded: map[string]struct{}{}}
+			constraints[f.Field] = c
+			fields = append(fields, f.Field)
+		}
+
+		switch f.Op {
+		case EqualsOperator, InOperator:
+			values := map[string]struct{}{}
+			for _, v := range f.values() {
+				if _, ok := c.allowed[v]; ok || c.allowed == nil {
+					values[v] = struct{}{}
+				}
+			}
+			c.allowed = values
+		case NotEqualsOperator, NotInOperator:
+			for _, v := range f.values() {
+				c.excluded[v] = struct{}{}
+			}
+		}
+	}
+
+	for _, field := range fields {
+		c := constraints[field]
+		if c.allowed == nil {
+			continue
+		}
+
+		matchable := false
+		for v := range c.allowed {
+			if _, ok := c.excluded[v]; !ok {
+				matchable = true
+				break
+			}
+		}
+		if !matchable {
+			return field, true
+		}
+	}
+
+	return "", false
+}
+
+// isFilterField reports whether field can be used in filters. Computed
+// fields are always filterable. A dotted field is a path inside a JSONB
+// field and is allowed only when its first segment is an allowed field
+// declared as TypeJSONB.
+func isFilterField(field string, opts *Options) bool {
+	if _, ok := opts.allowedFilterFields[field]; ok {
+		return true
+	}
+
+	if _, ok := opts.computedFields[field]; ok {
+		return true
+	}
+
+	base, path, ok := strings.Cut(field, ".")
+	if !ok || opts.fieldTypes[base] != TypeJSONB {
+		return false
+	}
+
+	if _, ok := opts.allowedFilterFields[base]; !ok {
+		return false
+	}
+
+	return !slices.Contains(strings.Split(path, "."), "")
+}
+
+// validateStrictField checks that field is an identifier or a dotted
+// path of identifiers.
+func validateStrictField(field string) error {
+	for _, segment := range strings.Split(field, ".") {
+		if !identifierRegexp.MatchString(segment) {
+			return fmt.Errorf("invalid field name %q", field)
+		}
+	}
+
+	return nil
+}
+
+// validateStrictFilter runs the checks of WithStrictValidation on f.
+func validateStrictFilter(f Filter) error {
+	if err := validateStrictField(f.Field); err != nil {
+		return err
+	}
+
+	if _, ok := relationalOperators[f.Op]; !ok {
+		return fmt.Errorf("unknown relational operator %q", f.Op)
+	}
+
+	if f.ValueField != "" {
+		return validateStrictField(f.ValueField)
+	}
+
+	if !f.isNullCheck() && slices.Contains(f.values(), "") {
+		return fmt.Errorf("filter on field %q requires a non-empty value", f.Field)
+	}
+
+	return nil
+}
+
+// validateValueField checks a filter comparing two fields: the value
+// must be empty, the operator must be a comparison and the other field
+// must be allowed in the clause of the filter.
+func validateValueField(f Filter, clause string, isAllowed func(string) bool) error {
+	if f.Value != "" || f.Values != nil {
+		return fmt.Errorf("filter on field %q cannot set both value and value_field", f.Field)
+	}
+
+	switch f.Op {
+	case EqualsOperator, NotEqualsOperator,
+		GreaterThanOperator, GreaterThanEqualsOperator,
+		LowerThanOperator, LowerThanEqualsOperator:
+	default:
+		return fmt.Errorf("relational operator %q does not support value_field", f.Op)
+	}
+
+	if !isAllowed(f.ValueField) {
+		return fmt.Errorf("field %q not allowed in %s", f.ValueField, clause)
+	}
+
+	return nil
+}
+
+// GetSearchRequest retrieves the parsed SearchRequest stored in the
+// request context by NewSearchHandler. If no request is stored, it
+// returns nil.
+func GetSearchRequest(r *http.Request) *SearchRequest {
+	v := r.Context().Value(searchKey)
+	if v == nil {
+		return nil
+	}
+
+	s, ok := v.(*SearchRequest)
+	if !ok {
+		return nil
+	}
+
+	return s
+}
+
+// GetParseDuration returns the time NewSearchHandler spent parsing and
+// validating the search payload of r, and false when it was not
+// recorded with WithTimingInContext.
+func GetParseDuration(r *http.Request) (time.Duration, bool) {
+	d, ok := r.Context().Value(parseDurationKey).(time.Duration)
+	return d, ok
+}
+
+// GetRawSearchQuery returns the search payload of r as sent by the
+// client, before decoding, when stored with WithStoreRawQuery. It
+// returns "" when it was not stored or the payload was missing.
+func GetRawSearchQuery(r *http.Request) string {
+	raw, _ := r.Context().Value(rawQueryKey).(string)
+	return raw
+}
+
+// GetSearchRequestWithDefaults is like GetSearchRequest, but returns a
+// copy of the stored request with the defaults of opts applied: limit
+// and order by as done by NewSearchHandler, and a missing offset set to
+// 0 unless offsets are disabled or a cursor is used. It is meant for
+// handlers reachable through middleware chains with other options. The
+// stored request is not modified.
+func GetSearchRequestWithDefaults(r *http.Request, opts *Options) *SearchRequest {
+	s := GetSearchRequest(r)
+	if s == nil {
+		return nil
+	}
+
+	s = s.Clone()
+	applyDefaults(s, opts)
+
+	if s.Offset == nil && s.Cursor == nil && !opts.isOffsetDisabled {
+		s.Offset = ptr(0)
+	}
+
+	return s
+}
+
+// RequireSearch wraps next so that it is only called when the request
+// carries a SearchRequest, responding with 204 No Content otherwise.
+// It is meant to be used inside a NewSearchHandler middleware.
+func RequireSearch(next http.Handler) http.Handler {
+	return RequireSearchWithStatus(http.StatusNoContent, next)
+}
+
+// RequireSearchWithStatus is like RequireSearch but responds with status
+// when the request carries no SearchRequest.
+func RequireSearchWithStatus(status int, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetSearchRequest(r) == nil {
+			w.WriteHeader(status)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
 }
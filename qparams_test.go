@@ -3,10 +3,15 @@ package qparams
 import (
 	"context"
 	"errors"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"gotest.tools/v3/assert"
 )
 
@@ -104,6 +109,101 @@ func TestSetDefaultOrderFields(t *testing.T) {
 	assert.DeepEqual(t, defaultOrderFields, map[string]struct{}{"id": {}})
 }
 
+func TestWithSortableFields(t *testing.T) {
+	t.Parallel()
+
+	opts := NewOptions(
+		WithFilterFields("name", "created_at"),
+		WithSortableFields("created_at", "id"),
+	)
+
+	assert.DeepEqual(t, opts.AllowedFilterFields(), []string{"created_at", "name"})
+	assert.DeepEqual(t, opts.AllowedOrderFields(), []string{"created_at", "id"})
+
+	err := validateSearchRequest(&SearchRequest{OrderBy: []OrderClause{{Field: "name", Direction: OrderAsc}}}, opts)
+	assert.Error(t, err, `field "name" is not sortable`)
+}
+
+func TestNewOptionsWithoutOperators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "without logical operators",
+			opts:     []Option{WithLogicalOperators()},
+			expected: "qparams: at least one logical operator must be allowed",
+		},
+		{
+			name:     "without relational operators",
+			opts:     []Option{WithRelationalOperators()},
+			expected: "qparams: at least one relational operator must be allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				assert.Equal(t, recover(), tt.expected)
+			}()
+
+			NewOptions(tt.opts...)
+		})
+	}
+}
+
+func TestOptionsAccessors(t *testing.T) {
+	t.Parallel()
+
+	opts := NewOptions(
+		WithQueryParam("search"),
+		WithFilterFields("name", "email", "id"),
+		WithOrderFields("name", "created_at"),
+		WithLogicalOperators(OrOperator, AndOperator),
+		WithRelationalOperators(LikeOperator, EqualsOperator),
+	)
+
+	assert.Equal(t, opts.QueryParam(), "search")
+	assert.DeepEqual(t, opts.AllowedFilterFields(), []string{"email", "id", "name"})
+	assert.DeepEqual(t, opts.AllowedOrderFields(), []string{"created_at", "name"})
+	assert.DeepEqual(t, opts.AllowedLogicalOperators(), []LogicalOperator{AndOperator, OrOperator})
+	assert.DeepEqual(t, opts.AllowedRelationalOperators(), []RelationalOperator{EqualsOperator, LikeOperator})
+
+	disabled := NewOptions(
+		WithRelationalOperators(LikeOperator, EqualsOperator),
+		WithDisabledOperators(map[RelationalOperator]string{LikeOperator: "too slow"}),
+	)
+	assert.DeepEqual(t, disabled.AllowedRelationalOperators(), []RelationalOperator{EqualsOperator})
+
+	fields := opts.AllowedFilterFields()
+	fields[0] = "password"
+	assert.DeepEqual(t, opts.AllowedFilterFields(), []string{"email", "id", "name"})
+
+	_, ok := opts.Limit()
+	assert.Assert(t, !ok)
+
+	opts = NewOptions(
+		WithLimit(100),
+		WithDefaultLimit(20),
+		WithDefaultOrderBy(OrderClause{Field: "id", Direction: OrderDesc}),
+		WithSearchTermFields("name"),
+	)
+
+	limit, ok := opts.Limit()
+	assert.Assert(t, ok)
+	assert.Equal(t, limit, 100)
+
+	limit, ok = opts.DefaultLimit()
+	assert.Assert(t, ok)
+	assert.Equal(t, limit, 20)
+
+	assert.DeepEqual(t, opts.DefaultOrderBy(), []OrderClause{{Field: "id", Direction: OrderDesc}})
+	assert.DeepEqual(t, opts.SearchTermFields(), []string{"name"})
+}
+
 func TestWithQueryParam(t *testing.T) {
 	t.Parallel()
 
@@ -148,6 +248,45 @@ func TestWithRelationalOperators(t *testing.T) {
 	assert.DeepEqual(t, opts.allowedRelationalOperators, map[RelationalOperator]struct{}{EqualsOperator: {}})
 }
 
+func TestRelationalOperatorPresets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		option   Option
+		expected map[RelationalOperator]struct{}
+	}{
+		{
+			name:   "with text operators",
+			option: WithTextOperators(),
+			expected: map[RelationalOperator]struct{}{
+				EqualsOperator: {}, NotEqualsOperator: {}, LikeOperator: {}, ILikeOperator: {}, InOperator: {},
+			},
+		},
+		{
+			name:   "with comparison operators",
+			option: WithComparisonOperators(),
+			expected: map[RelationalOperator]struct{}{
+				EqualsOperator: {}, NotEqualsOperator: {},
+				GreaterThanOperator: {}, GreaterThanEqualsOperator: {}, LowerThanOperator: {}, LowerThanEqualsOperator: {},
+			},
+		},
+		{
+			name:     "with all operators",
+			option:   WithAllOperators(),
+			expected: relationalOperators,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{allowedRelationalOperators: map[RelationalOperator]struct{}{GreaterThanOperator: {}}}
+			tt.option(&opts)
+			assert.DeepEqual(t, opts.allowedRelationalOperators, tt.expected)
+		})
+	}
+}
+
 func TestWithLimit(t *testing.T) {
 	t.Parallel()
 
@@ -158,6 +297,21 @@ func TestWithLimit(t *testing.T) {
 	assert.Equal(t, *opts.limit, 50)
 }
 
+func TestWithDefaultLimit(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithDefaultLimit(20)
+	f(&opts)
+
+	assert.Equal(t, *opts.fallbackLimit, 20)
+
+	f = WithDefaultLimit(-1)
+	f(&opts)
+
+	assert.Assert(t, opts.fallbackLimit == nil)
+}
+
 func TestWithErrorHandler(t *testing.T) {
 	t.Parallel()
 
@@ -228,206 +382,2535 @@ func TestWithExtraOrderFields(t *testing.T) {
 	assert.DeepEqual(t, opts.allowedOrderFields, map[string]struct{}{"id": {}, "name": {}})
 }
 
-func TestNewSearchHandler(t *testing.T) {
+func TestWithFieldType(t *testing.T) {
 	t.Parallel()
 
-	makeRequest := func(t *testing.T, handler http.Handler, url string) *httptest.ResponseRecorder {
-		t.Helper()
-		req := httptest.NewRequest(http.MethodGet, url, nil)
-		rr := httptest.NewRecorder()
-		handler.ServeHTTP(rr, req)
-		return rr
+	opts := Options{}
+	f := WithFieldType("data", TypeJSONB)
+	f(&opts)
+
+	assert.DeepEqual(t, opts.fieldTypes, map[string]FieldType{"data": TypeJSONB})
+}
+
+func TestWithFieldEnum(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithFieldEnum("status", "active", "inactive")
+	f(&opts)
+
+	assert.DeepEqual(t, opts.fieldEnums, map[string]map[string]struct{}{"status": {"active": {}, "inactive": {}}})
+}
+
+func TestWithOrderFieldDirections(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithOrderFieldDirections("rank", OrderAsc)
+	f(&opts)
+
+	assert.DeepEqual(t, opts.orderFieldDirections, map[string]map[OrderDirection]struct{}{"rank": {OrderAsc: {}}})
+}
+
+func TestWithSoftDeleteColumn(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	WithSoftDeleteColumn("deleted_at")(&opts)
+	WithIncludeDeletedAllowed(true)(&opts)
+
+	assert.Equal(t, opts.softDeleteColumn, "deleted_at")
+	assert.Equal(t, opts.isIncludeDeletedAllowed, true)
+
+	// the injected isnull filter does not need the operator to be allowed
+	s, err := Parse(`{"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"alice"}]}}`, NewOptions(
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(EqualsOperator),
+		WithFilterFields("name"),
+		WithSoftDeleteColumn("deleted_at"),
+	))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s.Groups.Groups[1].Filters, []Filter{{Field: "deleted_at", Op: IsNullOperator}})
+}
+
+func TestWithComputedField(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithComputedField("name_ci", "lower(name) = lower(?)")
+	f(&opts)
+
+	assert.DeepEqual(t, opts.computedFields, map[string]string{"name_ci": "lower(name) = lower(?)"})
+}
+
+func TestUsedDeprecatedOperators(t *testing.T) {
+	t.Parallel()
+
+	search := &SearchRequest{
+		Groups: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "name", Op: LikeOperator, Value: "a%"}},
+			Groups: []FilterGroup{
+				{Op: OrOperator, Filters: []Filter{
+					{Field: "role", Op: InOperator, Values: []string{"admin"}},
+					{Field: "name", Op: LikeOperator, Value: "b%"},
+				}},
+			},
+		},
+		Having: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "total", Op: GreaterThanOperator, Value: "1"}},
+		},
 	}
+	opts := &Options{}
+	WithDeprecatedOperators(LikeOperator, InOperator)(opts)
+
+	assert.DeepEqual(t, usedDeprecatedOperators(search, opts), []RelationalOperator{InOperator, LikeOperator})
+	assert.Assert(t, usedDeprecatedOperators(search, &Options{}) == nil)
+}
+
+func TestContradictoryField(t *testing.T) {
+	t.Parallel()
 
 	tests := []struct {
-		name    string
-		path    string
-		handler http.Handler
-		check   func(t *testing.T, res *httptest.ResponseRecorder)
+		name          string
+		filters       []Filter
+		expected      string
+		expectedFound bool
 	}{
 		{
-			name: "with mandatory search",
-			path: "/search",
-			handler: NewSearchHandler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				t.Errorf("next handler should not be called when param is missing")
-			})),
-			check: func(t *testing.T, res *httptest.ResponseRecorder) {
-				assert.Equal(t, res.Code, http.StatusBadRequest)
+			name: "with in and nin excluding every value",
+			filters: []Filter{
+				{Field: "id", Op: InOperator, Values: []string{"1", "2"}},
+				{Field: "id", Op: NotInOperator, Values: []string{"1", "2", "3"}},
 			},
+			expected:      "id",
+			expectedFound: true,
 		},
 		{
-			name: "without mandatory search",
-			path: "/search",
-			handler: NewSearchHandler(WithSearchMandatory(false))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(http.StatusOK)
-			})),
-			check: func(t *testing.T, res *httptest.ResponseRecorder) {
-				assert.Equal(t, res.Code, http.StatusOK)
+			name: "with in and nin excluding some values",
+			filters: []Filter{
+				{Field: "id", Op: InOperator, Values: []string{"1", "2"}},
+				{Field: "id", Op: NotInOperator, Values: []string{"1"}},
 			},
 		},
 		{
-			name: "with invalid JSON",
-			path: "/search?q={notvalidJSON}",
-			handler: NewSearchHandler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				t.Errorf("next handler should not be called when JSON is invalid")
-			})),
-			check: func(t *testing.T, res *httptest.ResponseRecorder) {
-				assert.Equal(t, res.Code, http.StatusBadRequest)
+			name: "with eq and ne on same value",
+			filters: []Filter{
+				{Field: "name", Op: EqualsOperator, Value: "foo"},
+				{Field: "status", Op: EqualsOperator, Value: "active"},
+				{Field: "status", Op: NotEqualsOperator, Value: "active"},
 			},
+			expected:      "status",
+			expectedFound: true,
 		},
 		{
-			name: "with valid request",
-			path: `/search?q={"limit":10,"offset":0}`,
-			handler: NewSearchHandler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				req := GetSearchRequest(r)
-				if req == nil {
-					t.Fatal("expected SearchRequest in context")
-				}
-
-				expected := SearchRequest{
-					Limit:  ptr(10),
-					Offset: ptr(0),
-				}
-				assert.DeepEqual(t, req, ptr(expected))
-
-				w.WriteHeader(http.StatusOK)
-			})),
-			check: func(t *testing.T, res *httptest.ResponseRecorder) {
-				assert.Equal(t, res.Code, http.StatusOK)
+			name: "with eq on different values",
+			filters: []Filter{
+				{Field: "status", Op: EqualsOperator, Value: "active"},
+				{Field: "status", Op: EqualsOperator, Value: "inactive"},
+			},
+			expected:      "status",
+			expectedFound: true,
+		},
+		{
+			name: "with ne only",
+			filters: []Filter{
+				{Field: "status", Op: NotEqualsOperator, Value: "active"},
+				{Field: "status", Op: NotInOperator, Values: []string{"inactive"}},
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			rr := makeRequest(t, tt.handler, tt.path)
-			tt.check(t, rr)
+			field, ok := contradictoryField(tt.filters)
+			assert.Equal(t, ok, tt.expectedFound)
+			assert.Equal(t, field, tt.expected)
 		})
 	}
 }
 
-func TestValidateSearchRequest(t *testing.T) {
+func TestWithDefaultOrderBy(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithDefaultOrderBy(OrderClause{Field: "created_at", Direction: OrderDesc})
+	f(&opts)
+
+	assert.DeepEqual(t, opts.defaultOrderBy, []OrderClause{{Field: "created_at", Direction: OrderDesc}})
+}
+
+func TestWithMaxOffset(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	WithMaxOffset(100)(&opts)
+	assert.DeepEqual(t, opts.maxOffset, ptr(100))
+
+	WithMaxOffset(-1)(&opts)
+	assert.Assert(t, opts.maxOffset == nil)
+}
+
+func TestWithAllowedHavingFields(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithAllowedHavingFields("total")
+	f(&opts)
+
+	assert.DeepEqual(t, opts.allowedHavingFields, map[string]struct{}{"total": {}})
+}
+
+func TestWithRedactedFields(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithRedactedFields("email", "phone")
+	f(&opts)
+
+	assert.DeepEqual(t, opts.redactedFields, map[string]struct{}{"email": {}, "phone": {}})
+}
+
+func TestWithAllowDistinct(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithAllowDistinct(true)
+	f(&opts)
+
+	assert.Equal(t, opts.isDistinctAllowed, true)
+}
+
+func TestWithSortTiebreaker(t *testing.T) {
 	t.Parallel()
 
+	opts := NewOptions(
+		WithOrderFields("name", "id"),
+		WithDefaultOrderBy(OrderClause{Field: "name", Direction: OrderAsc}, OrderClause{Field: "name", Direction: OrderDesc}),
+		WithSortTiebreaker(OrderClause{Field: "id", Direction: OrderAsc}),
+	)
+
 	tests := []struct {
-		name   string
-		search SearchRequest
-		opts   Options
-		check  func(t *testing.T, err error)
+		name     string
+		raw      string
+		expected []OrderClause
 	}{
 		{
-			name: "with negative limit",
-			search: SearchRequest{
-				Limit: ptr(-5),
-			},
-			opts: Options{},
-			check: func(t *testing.T, err error) {
-				assert.ErrorContains(t, err, `limit must be null or >= 0`)
+			name: "without order by",
+			raw:  `{}`,
+			expected: []OrderClause{
+				{Field: "name", Direction: OrderAsc},
+				{Field: "id", Direction: OrderAsc},
 			},
 		},
 		{
-			name: "with null limit when mandatory",
-			search: SearchRequest{
-				Limit: nil,
-			},
-			opts: Options{
-				limit: ptr(10),
-			},
-			check: func(t *testing.T, err error) {
-				assert.ErrorContains(t, err, `limit is mandatory`)
-			},
+			name:     "with order by on the tiebreaker field",
+			raw:      `{"order_by":[{"field":"id","direction":"desc"}]}`,
+			expected: []OrderClause{{Field: "id", Direction: OrderDesc}},
 		},
 		{
-			name: "with too high limit",
-			search: SearchRequest{
-				Limit: ptr(1000),
-			},
-			opts: Options{
-				limit: ptr(10),
-			},
-			check: func(t *testing.T, err error) {
-				assert.ErrorContains(t, err, `limit must be between 0 and 10`)
+			name: "with order by on another field",
+			raw:  `{"order_by":[{"field":"name","direction":"desc"}]}`,
+			expected: []OrderClause{
+				{Field: "name", Direction: OrderDesc},
+				{Field: "id", Direction: OrderAsc},
 			},
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.raw, opts)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, s.OrderBy, tt.expected)
+		})
+	}
+}
+
+func TestWithBoolTokens(t *testing.T) {
+	t.Parallel()
+
+	newOpts := func(opts ...Option) *Options {
+		return NewOptions(append([]Option{
+			WithLogicalOperators(AndOperator),
+			WithRelationalOperators(EqualsOperator, InOperator),
+			WithFilterFields("active"),
+			WithFieldType("active", TypeBool),
+		}, opts...)...)
+	}
+	filter := func(value string) string {
+		return `{"groups":{"op":"and","filters":[{"field":"active","op":"eq","value":` + value + `}]}}`
+	}
+
+	tests := []struct {
+		name        string
+		raw         string
+		opts        *Options
+		expected    []string
+		expectedErr string
+	}{
 		{
-			name: "with negative offset",
-			search: SearchRequest{
-				Offset: ptr(-5),
-			},
-			opts: Options{},
-			check: func(t *testing.T, err error) {
-				assert.ErrorContains(t, err, `offset must be null or >= 0`)
-			},
+			name:     "with JSON boolean",
+			raw:      filter(`false`),
+			opts:     newOpts(),
+			expected: []string{"false"},
 		},
 		{
-			name: "with not allowed order field",
-			search: SearchRequest{
-				OrderBy: []OrderClause{
-					{Field: "notAllowedField", Direction: OrderAsc},
-				},
-			},
-			opts: Options{
-				allowedOrderFields: map[string]struct{}{"name": {}},
-			},
-			check: func(t *testing.T, err error) {
-				assert.ErrorContains(t, err, `field "notAllowedField" not allowed in order by`)
-			},
+			name:     "with default token",
+			raw:      filter(`"TRUE"`),
+			opts:     newOpts(),
+			expected: []string{"true"},
 		},
 		{
-			name: "with not allowed filter field",
-			search: SearchRequest{
-				Groups: &FilterGroup{
-					Op: AndOperator,
-					Filters: []Filter{
-						{Field: "notAllowedField", Op: EqualsOperator, Value: "foo"},
-					},
+			name:        "with token not allowed by default",
+			raw:         filter(`"yes"`),
+			opts:        newOpts(),
+			expectedErr: `value "yes" is not a valid boolean`,
+		},
+		{
+			name:     "with custom tokens",
+			raw:      `{"groups":{"op":"and","filters":[{"field":"active","op":"in","value":["Yes","0"]}]}}`,
+			opts:     newOpts(WithBoolTokens([]string{"yes", "1"}, []string{"no", "0"})),
+			expected: []string{"true", "false"},
+		},
+		{
+			name:        "with invalid custom token",
+			raw:         filter(`"true"`),
+			opts:        newOpts(WithBoolTokens([]string{"yes"}, []string{"no"})),
+			expectedErr: `value "true" is not a valid boolean`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.raw, tt.opts)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, s.Groups.Filters[0].values(), tt.expected)
+		})
+	}
+}
+
+func TestWithValuePlaceholders(t *testing.T) {
+	t.Parallel()
+
+	type userKey struct{}
+
+	var got *SearchRequest
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = GetSearchRequest(r)
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := NewSearchHandler(
+		WithQueryParam("q"),
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(EqualsOperator, InOperator),
+		WithFilterFields("owner_id"),
+		WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+			http.Error(w, err.Error(), StatusCode(err))
+		}),
+		WithValuePlaceholders(map[string]func(*http.Request) string{
+			"$me": func(r *http.Request) string { return r.Context().Value(userKey{}).(string) },
+		}),
+	)(next)
+
+	tests := []struct {
+		name           string
+		filter         string
+		expectedStatus int
+		expected       []string
+	}{
+		{
+			name:           "with placeholder",
+			filter:         `{"field":"owner_id","op":"eq","value":"$me"}`,
+			expectedStatus: http.StatusOK,
+			expected:       []string{"42"},
+		},
+		{
+			name:           "with placeholder in list",
+			filter:         `{"field":"owner_id","op":"in","value":["$me","7"]}`,
+			expectedStatus: http.StatusOK,
+			expected:       []string{"42", "7"},
+		},
+		{
+			name:           "with unknown placeholder",
+			filter:         `{"field":"owner_id","op":"eq","value":"$you"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+
+			q := `{"groups":{"op":"and","filters":[` + tt.filter + `]}}`
+			req := httptest.NewRequest(http.MethodGet, "/?q="+url.QueryEscape(q), nil)
+			req = req.WithContext(context.WithValue(req.Context(), userKey{}, "42"))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, rr.Code, tt.expectedStatus)
+			if tt.expected != nil {
+				assert.DeepEqual(t, got.Groups.Filters[0].values(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithDisabledOperators(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithDisabledOperators(map[RelationalOperator]string{InOperator: "disabled"})
+	f(&opts)
+
+	assert.DeepEqual(t, opts.disabledOperators, map[RelationalOperator]string{InOperator: "disabled"})
+}
+
+func TestWithStrictValidation(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithStrictValidation(true)
+	f(&opts)
+
+	assert.Equal(t, opts.isStrictValidation, true)
+}
+
+func TestWithSkipUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	raw := `{"groups":{"op":"and","filters":[` +
+		`{"field":"name","op":"eq","value":"alice"},` +
+		`{"field":"secret","op":"eq","value":"x"}],` +
+		`"groups":[{"op":"or","filters":[{"field":"name","op":"gt","value_field":"nickname"}]}]},` +
+		`"order_by":[{"field":"rank","direction":"desc"},{"field":"name","direction":"asc"}]}`
+
+	newOpts := func(value bool) *Options {
+		return NewOptions(
+			WithLogicalOperators(AndOperator, OrOperator),
+			WithRelationalOperators(EqualsOperator, GreaterThanOperator),
+			WithFilterFields("name"),
+			WithOrderFields("name"),
+			WithSkipUnknownFields(value),
+		)
+	}
+
+	s, err := Parse(raw, newOpts(true))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s.Groups, &FilterGroup{
+		Op:      AndOperator,
+		Filters: []Filter{{Field: "name", Op: EqualsOperator, Value: "alice"}},
+		Groups:  []FilterGroup{{Op: OrOperator, Filters: []Filter{}}},
+	})
+	assert.DeepEqual(t, s.OrderBy, []OrderClause{{Field: "name", Direction: OrderAsc}})
+	assert.DeepEqual(t, s.SkippedFields(), []string{"secret", "nickname", "rank"})
+
+	_, err = Parse(raw, newOpts(false))
+	assert.ErrorContains(t, err, `field "rank" not allowed in order by`)
+}
+
+func TestWithDirectionAliases(t *testing.T) {
+	t.Parallel()
+
+	aliases := map[string]OrderDirection{"descending": OrderDesc}
+
+	opts := Options{}
+	f := WithDirectionAliases(aliases)
+	f(&opts)
+
+	aliases["ascending"] = OrderAsc
+
+	assert.DeepEqual(t, opts.directionAliases, map[string]OrderDirection{"descending": OrderDesc})
+}
+
+func TestWithValidateBetweenOrder(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithValidateBetweenOrder(true)
+	f(&opts)
+
+	assert.Equal(t, opts.isBetweenOrderValidated, true)
+}
+
+func TestWithCollectAllErrors(t *testing.T) {
+	t.Parallel()
+
+	raw := `{"groups":{"op":"and","filters":[` +
+		`{"field":"status","op":"eq","value":"x"},{"field":"name","op":"like","value":"a%"},{"field":"age","op":"regex","value":"1"}` +
+		`]},"order_by":[{"field":"age","direction":"asc"}],"limit":-1}`
+
+	opts := []Option{
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(EqualsOperator, LikeOperator),
+		WithFilterFields("name", "age"),
+		WithOrderFields("name"),
+	}
+
+	_, err := Parse(raw, NewOptions(opts...))
+	assert.DeepEqual(t, FieldErrors(err), map[string]string{"limit": "limit must be null or >= 0"})
+
+	_, err = Parse(raw, NewOptions(append(opts, WithCollectAllErrors(true))...))
+	assert.Equal(t, StatusCode(err), http.StatusUnprocessableEntity)
+	assert.DeepEqual(t, FieldErrors(err), map[string]string{
+		"limit":  "limit must be null or >= 0",
+		"age":    `field "age" is not sortable; relational operator "regex" not allowed for field "age"`,
+		"status": `field "status" not allowed in filters`,
+	})
+}
+
+func TestWithRequireFilters(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithRequireFilters(true)
+	f(&opts)
+
+	assert.Equal(t, opts.isFiltersRequired, true)
+}
+
+func TestWithFlatFiltersOnly(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithFlatFiltersOnly(true)
+	f(&opts)
+
+	assert.Equal(t, opts.isFlatFiltersOnly, true)
+}
+
+func TestWithCaseInsensitiveFields(t *testing.T) {
+	t.Parallel()
+
+	newOpts := func(value bool) *Options {
+		return NewOptions(
+			WithLogicalOperators(AndOperator),
+			WithRelationalOperators(EqualsOperator),
+			WithFilterFields("Name", "data"),
+			WithFieldType("data", TypeJSONB),
+			WithOrderFields("created_at"),
+			WithCaseInsensitiveFields(value),
+		)
+	}
+
+	raw := `{"groups":{"op":"and","filters":[` +
+		`{"field":"NAME","op":"eq","value":"alice"},` +
+		`{"field":"Data.Country","op":"eq","value":"IT"}]},` +
+		`"order_by":[{"field":"Created_At","direction":"desc"}]}`
+
+	s, err := Parse(raw, newOpts(true))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s.Groups.Filters, []Filter{
+		{Field: "name", Op: EqualsOperator, Value: "alice"},
+		{Field: "data.Country", Op: EqualsOperator, Value: "IT"},
+	})
+	assert.DeepEqual(t, s.OrderBy, []OrderClause{{Field: "created_at", Direction: OrderDesc}})
+
+	_, err = Parse(`{"groups":{"op":"and","filters":[{"field":"NAME","op":"eq","value":"alice"}]}}`, newOpts(false))
+	assert.ErrorContains(t, err, `field "NAME" not allowed in filters`)
+
+	_, err = Parse(`{"order_by":[{"field":"Created_At","direction":"asc"}]}`, newOpts(false))
+	assert.ErrorContains(t, err, `field "Created_At" not allowed in order by`)
+}
+
+func TestWithCaseInsensitiveFieldOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := NewOptions(
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(EqualsOperator, GreaterThanOperator, LikeOperator),
+		WithFieldSpec([]FieldSpec{
+			{Name: "Status", Filterable: true, Enum: []string{"active"}},
+			{Name: "Age", Filterable: true, Type: TypeNumber},
+			{Name: "Name", Filterable: true, AllowedOps: []RelationalOperator{LikeOperator}},
+		}),
+		WithCaseInsensitiveFields(true),
+	)
+
+	tests := []struct {
+		name        string
+		filter      string
+		expectedErr string
+	}{
+		{
+			name:   "with enum value",
+			filter: `{"field":"STATUS","op":"eq","value":"active"}`,
+		},
+		{
+			name:        "with value outside enum",
+			filter:      `{"field":"Status","op":"eq","value":"hacked"}`,
+			expectedErr: `value "hacked" not allowed for field "status"`,
+		},
+		{
+			name:   "with typed value",
+			filter: `{"field":"AGE","op":"gt","value":"18"}`,
+		},
+		{
+			name:        "with operator not allowed on field type",
+			filter:      `{"field":"Age","op":"like","value":"1%"}`,
+			expectedErr: `operator "like" incompatible with field type number`,
+		},
+		{
+			name:   "with field operator",
+			filter: `{"field":"NAME","op":"like","value":"al%"}`,
+		},
+		{
+			name:        "with operator not allowed on field",
+			filter:      `{"field":"Name","op":"eq","value":"alice"}`,
+			expectedErr: `field "name"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(`{"groups":{"op":"and","filters":[`+tt.filter+`]}}`, opts)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NilError(t, err)
+			}
+		})
+	}
+}
+
+func TestWithFieldAlias(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithFieldAlias("sort", "order_by")
+	f(&opts)
+
+	assert.DeepEqual(t, opts.keyAliases, map[string]string{"sort": "order_by"})
+}
+
+func TestWithSearchTermFields(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithSearchTermFields("name", "email")
+	f(&opts)
+
+	assert.DeepEqual(t, opts.searchTermFields, []string{"name", "email"})
+}
+
+func TestExpandSearchTerm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		search   SearchRequest
+		opts     Options
+		expected *FilterGroup
+	}{
+		{
+			name:     "without term",
+			search:   SearchRequest{},
+			opts:     Options{searchTermFields: []string{"name"}},
+			expected: nil,
+		},
+		{
+			name:   "with term and no groups",
+			search: SearchRequest{Term: ptr("alice")},
+			opts:   Options{searchTermFields: []string{"name", "email"}},
+			expected: &FilterGroup{
+				Op: OrOperator,
+				Filters: []Filter{
+					{Field: "name", Op: ILikeOperator, Value: "%alice%"},
+					{Field: "email", Op: ILikeOperator, Value: "%alice%"},
+				},
+			},
+		},
+		{
+			name: "with term and groups",
+			search: SearchRequest{
+				Term: ptr("alice"),
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+				},
+			},
+			opts: Options{searchTermFields: []string{"name"}},
+			expected: &FilterGroup{
+				Op: AndOperator,
+				Groups: []FilterGroup{
+					{
+						Op:      AndOperator,
+						Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+					},
+					{
+						Op:      OrOperator,
+						Filters: []Filter{{Field: "name", Op: ILikeOperator, Value: "%alice%"}},
+					},
+				},
+			},
+		},
+		{
+			name:   "with wildcards in term",
+			search: SearchRequest{Term: ptr(`50%_off\`)},
+			opts:   Options{searchTermFields: []string{"name"}},
+			expected: &FilterGroup{
+				Op:      OrOperator,
+				Filters: []Filter{{Field: "name", Op: ILikeOperator, Value: `%50\%\_off\\%`}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expandSearchTerm(&tt.search, &tt.opts)
+			assert.DeepEqual(t, tt.search.Groups, tt.expected)
+		})
+	}
+}
+
+func TestWithOffsetDisabled(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithOffsetDisabled(true)
+	f(&opts)
+
+	assert.Equal(t, opts.isOffsetDisabled, true)
+}
+
+func TestWithMaxOrderFields(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithMaxOrderFields(2)
+	f(&opts)
+
+	assert.Equal(t, *opts.maxOrderFields, 2)
+}
+
+func TestWithMaxPayloadSize(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithMaxPayloadSize(1024)
+	f(&opts)
+
+	assert.Equal(t, *opts.maxPayloadSize, 1024)
+}
+
+func TestWithMaxRegexLength(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithMaxRegexLength(64)
+	f(&opts)
+
+	assert.Equal(t, *opts.maxRegexLength, 64)
+
+	f = WithMaxRegexLength(-1)
+	f(&opts)
+
+	assert.Assert(t, opts.maxRegexLength == nil)
+}
+
+func TestWithMaxValueLength(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithMaxValueLength(256)
+	f(&opts)
+
+	assert.Equal(t, *opts.maxValueLength, 256)
+
+	f = WithMaxValueLength(-1)
+	f(&opts)
+
+	assert.Assert(t, opts.maxValueLength == nil)
+}
+
+func TestTransformValues(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	WithValueTransformer(LikeOperator, strings.TrimSpace)(&opts)
+	WithValueTransformer(LikeOperator, strings.ToLower)(&opts)
+	WithValueTransformer(InOperator, strings.ToUpper)(&opts)
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op: AndOperator,
+			Filters: []Filter{
+				{Field: "name", Op: LikeOperator, Value: "  Alice%  "},
+				{Field: "name", Op: EqualsOperator, Value: " Bob "},
+			},
+			Groups: []FilterGroup{
+				{Op: OrOperator, Filters: []Filter{{Field: "code", Op: InOperator, Values: []string{"it", "fr"}}}},
+			},
+		},
+	}
+
+	transformValues(&search, &opts)
+
+	assert.DeepEqual(t, search.Groups, &FilterGroup{
+		Op: AndOperator,
+		Filters: []Filter{
+			{Field: "name", Op: LikeOperator, Value: "alice%"},
+			{Field: "name", Op: EqualsOperator, Value: " Bob "},
+		},
+		Groups: []FilterGroup{
+			{Op: OrOperator, Filters: []Filter{{Field: "code", Op: InOperator, Values: []string{"IT", "FR"}}}},
+		},
+	})
+}
+
+func TestWithCostBudget(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	f := WithCostBudget(10, nil)
+	f(&opts)
+
+	assert.Equal(t, *opts.costBudget, 10)
+	assert.Equal(t, opts.cost(SearchStats{FilterCount: 3, MaxDepth: 2}), 7)
+
+	f = WithCostBudget(-1, func(s SearchStats) int { return s.GroupCount })
+	f(&opts)
+
+	assert.Assert(t, opts.costBudget == nil)
+	assert.Equal(t, opts.cost(SearchStats{GroupCount: 4}), 4)
+}
+
+func TestNewSearchHandler(t *testing.T) {
+	t.Parallel()
+
+	makeRequest := func(t *testing.T, handler http.Handler, url string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		handler http.Handler
+		check   func(t *testing.T, res *httptest.ResponseRecorder)
+	}{
+		{
+			name: "with mandatory search",
+			path: "/search",
+			handler: NewSearchHandler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("next handler should not be called when param is missing")
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusBadRequest)
+			},
+		},
+		{
+			name: "without mandatory search",
+			path: "/search",
+			handler: NewSearchHandler(WithSearchMandatory(false))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+			},
+		},
+		{
+			name: "with invalid JSON",
+			path: "/search?q={notvalidJSON}",
+			handler: NewSearchHandler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("next handler should not be called when JSON is invalid")
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusBadRequest)
+			},
+		},
+		{
+			name: "with valid request",
+			path: `/search?q={"limit":10,"offset":0}`,
+			handler: NewSearchHandler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req := GetSearchRequest(r)
+				if req == nil {
+					t.Fatal("expected SearchRequest in context")
+				}
+
+				expected := SearchRequest{
+					Limit:  ptr(10),
+					Offset: ptr(0),
+				}
+				assert.DeepEqual(t, req, ptr(expected), cmpopts.IgnoreUnexported(SearchRequest{}))
+
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+			},
+		},
+		{
+			name: "with unescaped payload",
+			path: `/search?q={"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"tom&jerry"}]}}`,
+			handler: NewSearchHandler(WithFilterFields("name"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req := GetSearchRequest(r)
+				if req == nil {
+					t.Fatal("expected SearchRequest in context")
+				}
+
+				assert.Equal(t, req.Groups.Filters[0].Value, "tom&jerry")
+
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+			},
+		},
+		{
+			name: "with oversized unescaped payload",
+			path: `/search?q={"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"tom&` + strings.Repeat("x", 200) + `"}]}}`,
+			handler: NewSearchHandler(
+				WithQueryParam("q"),
+				WithFilterFields("name"),
+				WithMaxPayloadSize(100),
+				WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+					http.Error(w, err.Error(), StatusCode(err))
+				}),
+			)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("expected handler not to be called")
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusRequestEntityTooLarge)
+			},
+		},
+		{
+			name: "with separate pagination params",
+			path: `/search?q={}&limit=20&offset=40`,
+			handler: NewSearchHandler(WithSeparatePaginationParams("limit", "offset"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req := GetSearchRequest(r)
+				if req == nil {
+					t.Fatal("expected SearchRequest in context")
+				}
+
+				assert.DeepEqual(t, req, &SearchRequest{Limit: ptr(20), Offset: ptr(40)}, cmpopts.IgnoreUnexported(SearchRequest{}))
+
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+			},
+		},
+		{
+			name: "with separate pagination params only",
+			path: `/search?limit=20`,
+			handler: NewSearchHandler(WithSeparatePaginationParams("limit", "offset"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req := GetSearchRequest(r)
+				if req == nil {
+					t.Fatal("expected SearchRequest in context")
+				}
+
+				assert.DeepEqual(t, req, &SearchRequest{Limit: ptr(20)}, cmpopts.IgnoreUnexported(SearchRequest{}))
+
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+			},
+		},
+		{
+			name: "with limit in payload and query param",
+			path: `/search?q={"limit":10}&limit=20`,
+			handler: NewSearchHandler(WithSeparatePaginationParams("limit", "offset"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("next handler should not be called when limit is set twice")
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusBadRequest)
+			},
+		},
+		{
+			name: "with invalid offset query param",
+			path: `/search?q={}&offset=abc`,
+			handler: NewSearchHandler(WithSeparatePaginationParams("limit", "offset"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("next handler should not be called when offset is invalid")
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusBadRequest)
+			},
+		},
+		{
+			name: "with deprecated operator",
+			path: `/search?q={"groups":{"op":"and","filters":[{"field":"name","op":"like","value":"a"}]}}`,
+			handler: NewSearchHandler(
+				WithFilterFields("name"),
+				WithRelationalOperators(LikeOperator, ILikeOperator),
+				WithDeprecatedOperators(LikeOperator),
+				WithDeprecationHeader(true),
+			)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+				assert.Equal(t, res.Header().Get("Deprecation"), "true")
+			},
+		},
+		{
+			name: "without deprecated operator",
+			path: `/search?q={"groups":{"op":"and","filters":[{"field":"name","op":"ilike","value":"a"}]}}`,
+			handler: NewSearchHandler(
+				WithFilterFields("name"),
+				WithRelationalOperators(LikeOperator, ILikeOperator),
+				WithDeprecatedOperators(LikeOperator),
+				WithDeprecationHeader(true),
+			)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+				assert.Equal(t, res.Header().Get("Deprecation"), "")
+			},
+		},
+		{
+			name: "with request id header",
+			path: `/search?q={"limit":5}`,
+			handler: NewSearchHandler(
+				WithQueryParam("q"),
+				WithRequestIDHeader("X-Search-Id"),
+			)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+				assert.Equal(t, res.Header().Get("X-Search-Id"), (&SearchRequest{Limit: ptr(5)}).CacheKey())
+			},
+		},
+		{
+			name: "with pagination headers",
+			path: `/search?q={"offset":40}`,
+			handler: NewSearchHandler(
+				WithQueryParam("q"),
+				WithLimit(50),
+				WithDefaultLimit(20),
+				WithPaginationHeaders(true),
+			)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+				assert.Equal(t, res.Header().Get("X-Limit"), "20")
+				assert.Equal(t, res.Header().Get("X-Offset"), "40")
+			},
+		},
+		{
+			name: "with pagination headers and no offset",
+			path: `/search?q={}`,
+			handler: NewSearchHandler(
+				WithQueryParam("q"),
+				WithLimit(50),
+				WithPaginationHeaders(true),
+			)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+				assert.Equal(t, res.Header().Get("X-Limit"), "50")
+				assert.Equal(t, res.Header().Get("X-Offset"), "0")
+			},
+		},
+		{
+			name: "without pagination headers",
+			path: `/search?q={"limit":5}`,
+			handler: NewSearchHandler(
+				WithQueryParam("q"),
+			)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})),
+			check: func(t *testing.T, res *httptest.ResponseRecorder) {
+				assert.Equal(t, res.Code, http.StatusOK)
+				assert.Equal(t, res.Header().Get("X-Limit"), "")
+				assert.Equal(t, res.Header().Get("X-Offset"), "")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := makeRequest(t, tt.handler, tt.path)
+			tt.check(t, rr)
+		})
+	}
+}
+
+func TestExcludeSoftDeleted(t *testing.T) {
+	t.Parallel()
+
+	deleted := FilterGroup{
+		Op:      AndOperator,
+		Filters: []Filter{{Field: "deleted_at", Op: IsNullOperator}},
+	}
+
+	tests := []struct {
+		name     string
+		search   SearchRequest
+		opts     Options
+		expected *FilterGroup
+	}{
+		{
+			name:     "without soft delete column",
+			search:   SearchRequest{},
+			opts:     Options{},
+			expected: nil,
+		},
+		{
+			name:     "with no groups",
+			search:   SearchRequest{},
+			opts:     Options{softDeleteColumn: "deleted_at"},
+			expected: &deleted,
+		},
+		{
+			name: "with groups",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      OrOperator,
+					Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+				},
+			},
+			opts: Options{softDeleteColumn: "deleted_at"},
+			expected: &FilterGroup{
+				Op: AndOperator,
+				Groups: []FilterGroup{
+					{
+						Op:      OrOperator,
+						Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+					},
+					deleted,
+				},
+			},
+		},
+		{
+			name:     "with deleted rows included",
+			search:   SearchRequest{IncludeDeleted: true},
+			opts:     Options{softDeleteColumn: "deleted_at", isIncludeDeletedAllowed: true},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			excludeSoftDeleted(&tt.search, &tt.opts)
+			assert.DeepEqual(t, tt.search.Groups, tt.expected)
+		})
+	}
+}
+
+func TestLimitResolution(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		max           *int
+		fallback      *int
+		requested     *int
+		expectedLimit *int
+		expectedErr   string
+	}{
+		{
+			name:          "without max, default and requested limit",
+			expectedLimit: nil,
+		},
+		{
+			name:          "with requested limit only",
+			requested:     ptr(30),
+			expectedLimit: ptr(30),
+		},
+		{
+			name:          "with default limit only",
+			fallback:      ptr(20),
+			expectedLimit: ptr(20),
+		},
+		{
+			name:          "with default and requested limit",
+			fallback:      ptr(20),
+			requested:     ptr(30),
+			expectedLimit: ptr(30),
+		},
+		{
+			name:          "with max limit only",
+			max:           ptr(50),
+			expectedLimit: ptr(50),
+		},
+		{
+			name:          "with max and requested limit",
+			max:           ptr(50),
+			requested:     ptr(30),
+			expectedLimit: ptr(30),
+		},
+		{
+			name:        "with max and too high requested limit",
+			max:         ptr(50),
+			requested:   ptr(60),
+			expectedErr: "limit must be between 0 and 50",
+		},
+		{
+			name:          "with max and default limit",
+			max:           ptr(50),
+			fallback:      ptr(20),
+			expectedLimit: ptr(20),
+		},
+		{
+			name:        "with default limit higher than max",
+			max:         ptr(50),
+			fallback:    ptr(60),
+			expectedErr: "limit must be between 0 and 50",
+		},
+		{
+			name:          "with max, default and requested limit",
+			max:           ptr(50),
+			fallback:      ptr(20),
+			requested:     ptr(30),
+			expectedLimit: ptr(30),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{limit: tt.max, fallbackLimit: tt.fallback}
+			search := SearchRequest{Limit: tt.requested}
+
+			applyDefaults(&search, &opts)
+			err := validateSearchRequest(&search, &opts)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, search.Limit, tt.expectedLimit)
+		})
+	}
+}
+
+func TestValidateSearchRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		search SearchRequest
+		opts   Options
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name: "with negative limit",
+			search: SearchRequest{
+				Limit: ptr(-5),
+			},
+			opts: Options{},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `limit must be null or >= 0`)
+			},
+		},
+		{
+			name: "with too high limit",
+			search: SearchRequest{
+				Limit: ptr(1000),
+			},
+			opts: Options{
+				limit: ptr(10),
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `limit must be between 0 and 10`)
+			},
+		},
+		{
+			name: "with negative offset",
+			search: SearchRequest{
+				Offset: ptr(-5),
+			},
+			opts: Options{},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `offset must be null or >= 0`)
+			},
+		},
+		{
+			name: "with term and no term fields",
+			search: SearchRequest{
+				Term: ptr("alice"),
+			},
+			opts: Options{},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `term search not allowed`)
+			},
+		},
+		{
+			name: "with not allowed order field",
+			search: SearchRequest{
+				OrderBy: []OrderClause{
+					{Field: "notAllowedField", Direction: OrderAsc},
+				},
+			},
+			opts: Options{
+				allowedOrderFields: map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `field "notAllowedField" not allowed in order by`)
+			},
+		},
+		{
+			name: "with not allowed filter field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "notAllowedField", Op: EqualsOperator, Value: "foo"},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `field "notAllowedField" not allowed in filters`)
+			},
+		},
+		{
+			name: "with not allowed relational operator",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "name", Op: NotEqualsOperator, Value: "foo"},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: map[RelationalOperator]struct{}{EqualsOperator: {}},
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `relational operator "ne" not allowed for field "name"`)
+			},
+		},
+		{
+			name: "with not allowed logical operator",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "name", Op: NotEqualsOperator, Value: "foo"},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    map[LogicalOperator]struct{}{OrOperator: {}},
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `logical operator "and" not allowed`)
+			},
+		},
+		{
+			name: "with value and value field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "updated_at", Op: GreaterThanOperator, Value: "foo", ValueField: "created_at"},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"updated_at": {}, "created_at": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `filter on field "updated_at" cannot set both value and value_field`)
+			},
+		},
+		{
+			name: "with not allowed value field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "updated_at", Op: GreaterThanOperator, ValueField: "password"},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"updated_at": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `field "password" not allowed in filters`)
+			},
+		},
+		{
+			name: "with value field and like operator",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "name", Op: LikeOperator, ValueField: "email"},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}, "email": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `relational operator "like" does not support value_field`)
+			},
+		},
+		{
+			name: "with valid value field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "updated_at", Op: GreaterThanOperator, ValueField: "created_at"},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"updated_at": {}, "created_at": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with query over cost budget",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "name", Op: EqualsOperator, Value: "foo"},
+						{Field: "name", Op: NotEqualsOperator, Value: "bar"},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+				costBudget:                 ptr(3),
+				cost:                       defaultCost,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `query too expensive`)
+			},
+		},
+		{
+			name: "with query within cost budget",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: EqualsOperator, Value: "foo"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+				costBudget:                 ptr(3),
+				cost:                       defaultCost,
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with allowed null-safe equality",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: EqualsNullSafeOperator, Value: "foo"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with not allowed null-safe equality",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: EqualsNullSafeOperator, Value: "foo"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: map[RelationalOperator]struct{}{EqualsOperator: {}},
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `relational operator "eqns" not allowed for field "name"`)
+			},
+		},
+		{
+			name: "with offset when disabled",
+			search: SearchRequest{
+				Offset: ptr(0),
+			},
+			opts: Options{
+				isOffsetDisabled: true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `offset is not supported; use cursor`)
+			},
+		},
+		{
+			name: "with too many order fields",
+			search: SearchRequest{
+				OrderBy: []OrderClause{
+					{Field: "name", Direction: OrderAsc},
+					{Field: "id", Direction: OrderDesc},
+				},
+			},
+			opts: Options{
+				maxOrderFields:     ptr(1),
+				allowedOrderFields: map[string]struct{}{"name": {}, "id": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `too many order fields: 2 > 1`)
+			},
+		},
+		{
+			name: "with empty in values",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: InOperator, Values: []string{}}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `"in" filter requires at least one value`)
+			},
+		},
+		{
+			name: "with list of values on scalar operator",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: EqualsOperator, Values: []string{"foo"}}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `relational operator "eq" does not accept a list of values`)
+			},
+		},
+		{
+			name: "with empty group without operator",
+			search: SearchRequest{
+				Groups: &FilterGroup{Filters: []Filter{}},
+			},
+			opts: Options{
+				allowedLogicalOperators: logicalOperators,
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with non empty group without operator",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Filters: []Filter{{Field: "name", Op: EqualsOperator, Value: "foo"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `logical operator "" not allowed`)
+			},
+		},
+		{
+			name: "with path in jsonb field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "data.address.country", Op: EqualsOperator, Value: "IT"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"data": {}},
+				fieldTypes:                 map[string]FieldType{"data": TypeJSONB},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with path in non jsonb field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name.country", Op: EqualsOperator, Value: "IT"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `field "name.country" not allowed in filters`)
+			},
+		},
+		{
+			name: "with empty path segment in jsonb field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "data..country", Op: EqualsOperator, Value: "IT"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"data": {}},
+				fieldTypes:                 map[string]FieldType{"data": TypeJSONB},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `field "data..country" not allowed in filters`)
+			},
+		},
+		{
+			name: "with array contains on array field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "tags", Op: ArrayContainsOperator, Values: []string{"go", "sql"}}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"tags": {}},
+				fieldTypes:                 map[string]FieldType{"tags": TypeArray},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with array contains on scalar field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: ArrayContainsOperator, Value: "foo"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `relational operator "array_contains" requires an array field, "name" is not`)
+			},
+		},
+		{
+			name: "with values in field enum",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "status", Op: EqualsOperator, Value: "active"},
+						{Field: "status", Op: InOperator, Values: []string{"active", "pending"}},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"status": {}},
+				fieldEnums:                 map[string]map[string]struct{}{"status": {"active": {}, "inactive": {}, "pending": {}}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with list value outside field enum",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "status", Op: InOperator, Values: []string{"active", "deleted"}}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"status": {}},
+				fieldEnums:                 map[string]map[string]struct{}{"status": {"active": {}, "inactive": {}}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `value "deleted" not allowed for field "status"`)
+			},
+		},
+		{
+			name:   "with deleted rows included but not allowed",
+			search: SearchRequest{IncludeDeleted: true},
+			opts:   Options{softDeleteColumn: "deleted_at"},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "include_deleted not allowed")
+			},
+		},
+		{
+			name: "with null check on enum field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "status", Op: IsNullOperator}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"status": {}},
+				fieldEnums:                 map[string]map[string]struct{}{"status": {"active": {}}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name:   "with distinct not allowed",
+			search: SearchRequest{Distinct: true},
+			opts:   Options{},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "distinct not allowed")
+			},
+		},
+		{
+			name:   "with distinct allowed",
+			search: SearchRequest{Distinct: true},
+			opts:   Options{isDistinctAllowed: true},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with allowed having field",
+			search: SearchRequest{
+				Having: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "total", Op: GreaterThanOperator, Value: "100"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedHavingFields:        map[string]struct{}{"total": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with filter field in having",
+			search: SearchRequest{
+				Having: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: EqualsOperator, Value: "foo"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+				allowedHavingFields:        map[string]struct{}{"total": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `field "name" not allowed in having`)
+			},
+		},
+		{
+			name: "with order only field in filters",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "created_at", Op: EqualsOperator, Value: "2024-01-01"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedOrderFields:         map[string]struct{}{"created_at": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `field "created_at" is not filterable`)
+			},
+		},
+		{
+			name:   "with filter only field in order by",
+			search: SearchRequest{OrderBy: []OrderClause{{Field: "name", Direction: OrderAsc}}},
+			opts: Options{
+				allowedFilterFields: map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `field "name" is not sortable`)
+			},
+		},
+		{
+			name:   "with offset above max offset",
+			search: SearchRequest{Offset: ptr(101)},
+			opts:   Options{maxOffset: ptr(100)},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "offset must be between 0 and 100")
+			},
+		},
+		{
+			name:   "with offset and cursor",
+			search: SearchRequest{Offset: ptr(10), Cursor: ptr("abc")},
+			opts:   Options{},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "cannot combine offset and cursor pagination")
+			},
+		},
+		{
+			name:   "with limit and cursor",
+			search: SearchRequest{Limit: ptr(10), Cursor: ptr("abc")},
+			opts:   Options{},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with computed field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name_ci", Op: EqualsOperator, Value: "alice"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				computedFields:             map[string]string{"name_ci": "lower(name) = lower(?)"},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with computed field and other operator",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name_ci", Op: LikeOperator, Value: "a%"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				computedFields:             map[string]string{"name_ci": "lower(name) = lower(?)"},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `computed field "name_ci" only supports "eq" with a value`)
+			},
+		},
+		{
+			name: "with operator not allowed by field spec",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "status", Op: LikeOperator, Value: "act%"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"status": {}},
+				fieldOperators:             map[string]map[RelationalOperator]struct{}{"status": {EqualsOperator: {}}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `relational operator "like" not allowed for field "status"`)
+			},
+		},
+		{
+			name: "with contradictory filters",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "id", Op: InOperator, Values: []string{"1"}},
+						{Field: "id", Op: NotInOperator, Values: []string{"1"}},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"id": {}},
+				isContradictionCheck:       true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `contradictory filters on field "id"`)
+			},
+		},
+		{
+			name: "with contradictory filters in or group",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: OrOperator,
+					Filters: []Filter{
+						{Field: "id", Op: EqualsOperator, Value: "1"},
+						{Field: "id", Op: NotEqualsOperator, Value: "1"},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"id": {}},
+				isContradictionCheck:       true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with nested groups and flat filters only",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "id", Op: EqualsOperator, Value: "1"}},
+					Groups: []FilterGroup{
+						{Op: OrOperator, Filters: []Filter{{Field: "id", Op: EqualsOperator, Value: "2"}}},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"id": {}},
+				isFlatFiltersOnly:          true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "nested filter groups are not supported")
+			},
+		},
+		{
+			name: "with single group and flat filters only",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: OrOperator,
+					Filters: []Filter{
+						{Field: "id", Op: EqualsOperator, Value: "1"},
+						{Field: "id", Op: EqualsOperator, Value: "2"},
+					},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"id": {}},
+				isFlatFiltersOnly:          true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with field ordered more than once",
+			search: SearchRequest{
+				OrderBy: []OrderClause{
+					{Field: "id", Direction: OrderAsc},
+					{Field: "id", Direction: OrderDesc},
+				},
+			},
+			opts: Options{
+				allowedOrderFields: map[string]struct{}{"id": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `field "id" ordered more than once`)
+			},
+		},
+		{
+			name:   "with limit at int max",
+			search: SearchRequest{Limit: ptr(math.MaxInt)},
+			opts:   Options{},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "limit too large")
+			},
+		},
+		{
+			name:   "with limit and offset overflowing",
+			search: SearchRequest{Limit: ptr(10), Offset: ptr(math.MaxInt - 10)},
+			opts:   Options{},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "limit too large")
+			},
+		},
+		{
+			name:   "with limit and offset at int max boundary",
+			search: SearchRequest{Limit: ptr(10), Offset: ptr(math.MaxInt - 11)},
+			opts:   Options{},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with disabled operator",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "id", Op: InOperator, Values: []string{"1", "2"}}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"id": {}},
+				disabledOperators:          map[RelationalOperator]string{InOperator: "the 'in' operator is disabled for performance reasons"},
+			},
+			check: func(t *testing.T, err error) {
+				assert.Error(t, err, "the 'in' operator is disabled for performance reasons")
+			},
+		},
+		{
+			name: "with strict validation and invalid field name",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name; drop", Op: EqualsOperator, Value: "x"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name; drop": {}},
+				isStrictValidation:         true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `invalid field name "name; drop"`)
+			},
+		},
+		{
+			name: "with strict validation and unknown operator",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: RelationalOperator("soundex"), Value: "x"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: map[RelationalOperator]struct{}{"soundex": {}},
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+				isStrictValidation:         true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `unknown relational operator "soundex"`)
+			},
+		},
+		{
+			name:   "with strict validation and unknown direction",
+			search: SearchRequest{OrderBy: []OrderClause{{Field: "name", Direction: "up"}}},
+			opts: Options{
+				allowedOrderFields: map[string]struct{}{"name": {}},
+				isStrictValidation: true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `invalid order direction "up" for field "name"`)
+			},
+		},
+		{
+			name: "with strict validation and empty value",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: EqualsOperator}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+				isStrictValidation:         true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `filter on field "name" requires a non-empty value`)
+			},
+		},
+		{
+			name: "with strict validation and valid filters",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "name", Op: EqualsOperator, Value: "alice"},
+						{Field: "deleted_at", Op: IsNullOperator},
+					},
+				},
+				OrderBy: []OrderClause{{Field: "name", Direction: OrderDesc}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}, "deleted_at": {}},
+				allowedOrderFields:         map[string]struct{}{"name": {}},
+				isStrictValidation:         true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with full-text filter on field without full-text config",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "body", Op: FullTextOperator, Value: "fox"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"body": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `relational operator "fts" requires a full-text field, "body" is not`)
+			},
+		},
+		{
+			name: "with full-text filter",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "body", Op: FullTextOperator, Value: "fox"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"body": {}},
+				fullTextFields:             map[string]FullTextConfig{"body": {Language: "english"}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with value too long",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: InOperator, Values: []string{"ok", "àèìòù!"}}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+				maxValueLength:             ptr(5),
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `value too long for field "name"`)
+			},
+		},
+		{
+			name: "with value at max length",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name", Op: EqualsOperator, Value: "àèìòù"}},
 				},
 			},
 			opts: Options{
 				allowedLogicalOperators:    logicalOperators,
 				allowedRelationalOperators: relationalOperators,
 				allowedFilterFields:        map[string]struct{}{"name": {}},
+				maxValueLength:             ptr(5),
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with filters required and none sent",
+			search: SearchRequest{
+				Groups:  &FilterGroup{Op: AndOperator, Groups: []FilterGroup{{Op: OrOperator}}},
+				OrderBy: []OrderClause{{Field: "id", Direction: OrderAsc}},
+				Limit:   ptr(10),
+			},
+			opts: Options{
+				allowedLogicalOperators: logicalOperators,
+				allowedOrderFields:      map[string]struct{}{"id": {}},
+				isFiltersRequired:       true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "at least one filter is required")
+			},
+		},
+		{
+			name: "with filters required and a nested filter",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Groups: []FilterGroup{
+					{Op: OrOperator, Filters: []Filter{{Field: "id", Op: EqualsOperator, Value: "1"}}},
+				}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"id": {}},
+				isFiltersRequired:          true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name:   "with filters required and a term",
+			search: SearchRequest{Term: ptr("alice")},
+			opts: Options{
+				searchTermFields:  []string{"name"},
+				isFiltersRequired: true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with regex not allowed by default",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "name", Op: RegexOperator, Value: "^a"}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: defaultRelationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `relational operator "regex" not allowed for field "name"`)
+			},
+		},
+		{
+			name: "with null checks allowed by default",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{
+					{Field: "manager_id", Op: IsNullOperator},
+					{Field: "manager_id", Op: IsNotNullOperator},
+				}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: withoutOperators(relationalOperators, optInOperators...),
+				allowedFilterFields:        map[string]struct{}{"manager_id": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with regex pattern too long",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "name", Op: IRegexOperator, Value: "(a+)+$"}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+				maxRegexLength:             ptr(5),
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `regex pattern too long for field "name"`)
+			},
+		},
+		{
+			name: "with regex pattern within max length",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "name", Op: RegexOperator, Value: "^al"}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+				maxRegexLength:             ptr(5),
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with between and a single value",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "price", Op: BetweenOperator, Values: []string{"1"}}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"price": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `"between" filter requires exactly two values`)
+			},
+		},
+		{
+			name: "with between bounds out of order not validated",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "price", Op: BetweenOperator, Values: []string{"100", "1"}}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"price": {}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with between numeric bounds out of order",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "price", Op: BetweenOperator, Values: []string{"100", "9.5"}}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"price": {}},
+				isBetweenOrderValidated:    true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `between bounds are out of order for field "price"`)
+			},
+		},
+		{
+			name: "with between time bounds out of order",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{
+					Field: "created_at", Op: BetweenOperator, Values: []string{"2024-02-01T00:00:00Z", "2024-01-01T00:00:00Z"},
+				}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"created_at": {}},
+				isBetweenOrderValidated:    true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `between bounds are out of order for field "created_at"`)
+			},
+		},
+		{
+			name: "with between bounds in order",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "price", Op: BetweenOperator, Values: []string{"9.5", "100"}}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"price": {}},
+				isBetweenOrderValidated:    true,
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with between text bounds",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "name", Op: BetweenOperator, Values: []string{"m", "a"}}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}},
+				isBetweenOrderValidated:    true,
 			},
 			check: func(t *testing.T, err error) {
-				assert.ErrorContains(t, err, `field "notAllowedField" not allowed in filters`)
+				assert.NilError(t, err)
 			},
 		},
 		{
-			name: "with not allowed relational operator",
+			name:   "with order direction not allowed for field",
+			search: SearchRequest{OrderBy: []OrderClause{{Field: "rank", Direction: OrderDesc}}},
+			opts: Options{
+				allowedOrderFields:   map[string]struct{}{"rank": {}},
+				orderFieldDirections: map[string]map[OrderDirection]struct{}{"rank": {OrderAsc: {}}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `direction "desc" not allowed for field "rank"`)
+			},
+		},
+		{
+			name:   "with order without direction allowed for field",
+			search: SearchRequest{OrderBy: []OrderClause{{Field: "rank"}, {Field: "name", Direction: OrderDesc}}},
+			opts: Options{
+				allowedOrderFields:   map[string]struct{}{"rank": {}, "name": {}},
+				orderFieldDirections: map[string]map[OrderDirection]struct{}{"rank": {OrderAsc: {}}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with range operator on bool field",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "active", Op: GreaterThanOperator, Value: "true"}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"active": {}},
+				fieldTypes:                 map[string]FieldType{"active": TypeBool},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `operator "gt" incompatible with field type bool`)
+			},
+		},
+		{
+			name: "with text operator on number field",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "age", Op: LikeOperator, Value: "4%"}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"age": {}},
+				fieldTypes:                 map[string]FieldType{"age": TypeNumber},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `operator "like" incompatible with field type number`)
+			},
+		},
+		{
+			name: "with equality on array field",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "tags", Op: EqualsOperator, Value: "go"}}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"tags": {}},
+				fieldTypes:                 map[string]FieldType{"tags": TypeArray},
+			},
+			check: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `operator "eq" incompatible with field type array`)
+			},
+		},
+		{
+			name: "with operators compatible with field types",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{
+					{Field: "name", Op: ILikeOperator, Value: "a%"},
+					{Field: "age", Op: BetweenOperator, Values: []string{"18", "65"}},
+					{Field: "active", Op: EqualsOperator, Value: "true"},
+					{Field: "tags", Op: IsNotNullOperator},
+				}},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"name": {}, "age": {}, "active": {}, "tags": {}},
+				fieldTypes: map[string]FieldType{
+					"name": TypeText, "age": TypeNumber, "active": TypeBool, "tags": TypeArray,
+				},
+			},
+			check: func(t *testing.T, err error) {
+				assert.NilError(t, err)
+			},
+		},
+		{
+			name: "with required field combination satisfied",
 			search: SearchRequest{
 				Groups: &FilterGroup{
 					Op: AndOperator,
 					Filters: []Filter{
-						{Field: "name", Op: NotEqualsOperator, Value: "foo"},
+						{Field: "tenant_id", Op: EqualsOperator, Value: "1"},
+						{Field: "created_at", Op: LowerThanOperator, Value: "2025-01-01"},
 					},
+					Groups: []FilterGroup{{
+						Op: OrOperator,
+						Filters: []Filter{
+							{Field: "created_at", Op: GreaterThanOperator, Value: "2024-06-01"},
+							{Field: "name", Op: EqualsOperator, Value: "alice"},
+						},
+					}},
 				},
 			},
 			opts: Options{
 				allowedLogicalOperators:    logicalOperators,
-				allowedRelationalOperators: map[RelationalOperator]struct{}{EqualsOperator: {}},
-				allowedFilterFields:        map[string]struct{}{"name": {}},
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"tenant_id": {}, "created_at": {}, "name": {}},
+				requiredFieldCombinations:  [][]string{{"tenant_id", "created_at"}},
 			},
 			check: func(t *testing.T, err error) {
-				assert.ErrorContains(t, err, `relational operator "ne" not allowed for field "name"`)
+				assert.NilError(t, err)
 			},
 		},
 		{
-			name: "with not allowed logical operator",
+			name: "with required field combination missing a field",
 			search: SearchRequest{
 				Groups: &FilterGroup{
-					Op: AndOperator,
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "created_at", Op: GreaterThanOperator, Value: "2024-01-01"}},
+				},
+			},
+			opts: Options{
+				allowedLogicalOperators:    logicalOperators,
+				allowedRelationalOperators: relationalOperators,
+				allowedFilterFields:        map[string]struct{}{"tenant_id": {}, "created_at": {}},
+				requiredFieldCombinations:  [][]string{{"tenant_id", "created_at"}},
+			},
+			check: func(t *testing.T, err error) {
+				assert.Error(t, err, `field "created_at" requires also filtering on "tenant_id"`)
+				assert.DeepEqual(t, FieldErrors(err), map[string]string{
+					"created_at": `field "created_at" requires also filtering on "tenant_id"`,
+				})
+			},
+		},
+		{
+			name: "with required field combination in an or group",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: OrOperator,
 					Filters: []Filter{
-						{Field: "name", Op: NotEqualsOperator, Value: "foo"},
+						{Field: "tenant_id", Op: EqualsOperator, Value: "1"},
+						{Field: "created_at", Op: GreaterThanOperator, Value: "2024-01-01"},
 					},
 				},
 			},
 			opts: Options{
-				allowedLogicalOperators:    map[LogicalOperator]struct{}{OrOperator: {}},
+				allowedLogicalOperators:    logicalOperators,
 				allowedRelationalOperators: relationalOperators,
-				allowedFilterFields:        map[string]struct{}{"name": {}},
+				allowedFilterFields:        map[string]struct{}{"tenant_id": {}, "created_at": {}},
+				requiredFieldCombinations:  [][]string{{"tenant_id", "created_at"}},
 			},
 			check: func(t *testing.T, err error) {
-				assert.ErrorContains(t, err, `logical operator "and" not allowed`)
+				assert.Error(t, err, `field "tenant_id" requires also filtering on "created_at"`)
 			},
 		},
 	}
@@ -440,6 +2923,251 @@ func TestValidateSearchRequest(t *testing.T) {
 	}
 }
 
+func BenchmarkValidateSearchRequest(b *testing.B) {
+	var nested func(depth int) FilterGroup
+	nested = func(depth int) FilterGroup {
+		g := FilterGroup{
+			Op: AndOperator,
+			Filters: []Filter{
+				{Field: "name", Op: EqualsOperator, Value: "foo"},
+				{Field: "name", Op: NotEqualsOperator, Value: "bar"},
+			},
+		}
+		if depth > 0 {
+			g.Groups = []FilterGroup{nested(depth - 1), nested(depth - 1)}
+		}
+		return g
+	}
+
+	root := nested(10)
+	search := SearchRequest{Groups: &root}
+	opts := Options{
+		allowedLogicalOperators:    logicalOperators,
+		allowedRelationalOperators: relationalOperators,
+		allowedFilterFields:        map[string]struct{}{"name": {}},
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := validateSearchRequest(&search, &opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRawJSONQueryValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		rawQuery      string
+		param         string
+		expected      string
+		expectedFound bool
+	}{
+		{
+			name:          "with encoded payload",
+			rawQuery:      "q=%7B%22limit%22%3A1%7D",
+			param:         "q",
+			expectedFound: false,
+		},
+		{
+			name:          "with missing param",
+			rawQuery:      "s={}",
+			param:         "q",
+			expectedFound: false,
+		},
+		{
+			name:          "with unescaped payload containing separators",
+			rawQuery:      `page=2&q={"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"a&b=}c"}]}}&x=1`,
+			param:         "q",
+			expected:      `{"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"a&b=}c"}]}}`,
+			expectedFound: true,
+		},
+		{
+			name:          "with unescaped truncated payload",
+			rawQuery:      `q={"limit":1`,
+			param:         "q",
+			expected:      `{"limit":1`,
+			expectedFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := rawJSONQueryValue(tt.rawQuery, tt.param)
+			assert.Equal(t, ok, tt.expectedFound)
+			assert.Equal(t, v, tt.expected)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		raw            string
+		opts           *Options
+		expected       *SearchRequest
+		expectedStatus int
+	}{
+		{
+			name:     "with default options",
+			raw:      `{"limit":5}`,
+			expected: &SearchRequest{Limit: ptr(5)},
+		},
+		{
+			name:     "with defaults applied",
+			raw:      `{}`,
+			opts:     NewOptions(WithLimit(20)),
+			expected: &SearchRequest{Limit: ptr(20)},
+		},
+		{
+			name:           "with malformed payload",
+			raw:            `{"limit":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "with invalid payload",
+			raw:            `{"order_by":[{"field":"name","direction":"asc"}]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "with aliased key",
+			raw:      `{"sort":[{"field":"name","direction":"asc"}]}`,
+			opts:     NewOptions(WithOrderFields("name"), WithFieldAlias("sort", "order_by")),
+			expected: &SearchRequest{OrderBy: []OrderClause{{Field: "name", Direction: OrderAsc}}},
+		},
+		{
+			name:     "with aliased and canonical keys",
+			raw:      `{"sort":[{"field":"id","direction":"asc"}],"order_by":[{"field":"name","direction":"desc"}]}`,
+			opts:     NewOptions(WithOrderFields("name"), WithFieldAlias("sort", "order_by")),
+			expected: &SearchRequest{OrderBy: []OrderClause{{Field: "name", Direction: OrderDesc}}},
+		},
+		{
+			name:           "with unknown key not aliased",
+			raw:            `{"sort":[{"field":"name","direction":"asc"}]}`,
+			opts:           NewOptions(WithOrderFields("name")),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "with direction aliases",
+			raw:  `{"order_by":[{"field":"name","direction":"descending"},{"field":"+id"},{"field":"-age"}]}`,
+			opts: NewOptions(WithOrderFields("name", "id", "age"), WithDirectionAliases(map[string]OrderDirection{
+				"ascending": OrderAsc, "descending": OrderDesc, "+": OrderAsc, "-": OrderDesc,
+			})),
+			expected: &SearchRequest{OrderBy: []OrderClause{
+				{Field: "name", Direction: OrderDesc},
+				{Field: "id", Direction: OrderAsc},
+				{Field: "age", Direction: OrderDesc},
+			}},
+		},
+		{
+			name:           "with unknown direction alias",
+			raw:            `{"order_by":[{"field":"name","direction":"up"}]}`,
+			opts:           NewOptions(WithOrderFields("name"), WithDirectionAliases(map[string]OrderDirection{"ascending": OrderAsc})),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "with payload too large",
+			raw:            `{"limit":5}`,
+			opts:           NewOptions(WithMaxPayloadSize(4)),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.raw, tt.opts)
+			if tt.expectedStatus != 0 {
+				assert.Equal(t, StatusCode(err), tt.expectedStatus)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, s, tt.expected, cmpopts.IgnoreUnexported(SearchRequest{}))
+		})
+	}
+}
+
+func TestParseSharedSteps(t *testing.T) {
+	t.Parallel()
+
+	opts := NewOptions(
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(EqualsOperator, GreaterThanOperator, LikeOperator),
+		WithFilterFields("owner_id", "created_at", "name"),
+		WithFieldType("created_at", TypeTime),
+		WithDeprecatedOperators(LikeOperator),
+		WithClock(func() time.Time { return time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC) }),
+		WithValuePlaceholders(map[string]func(*http.Request) string{
+			"$me": func(r *http.Request) string { return r.Header.Get("X-User") },
+		}),
+	)
+
+	s, err := Parse(`{"groups":{"op":"and","filters":[`+
+		`{"field":"owner_id","op":"eq","value":"$me"},`+
+		`{"field":"created_at","op":"gt","value":"$now-7d"},`+
+		`{"field":"name","op":"like","value":"al%"}]}}`, opts)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s.Groups.Filters, []Filter{
+		{Field: "owner_id", Op: EqualsOperator, Value: "$me"},
+		{Field: "created_at", Op: GreaterThanOperator, Value: "2024-03-03T08:00:00Z"},
+		{Field: "name", Op: LikeOperator, Value: "al%"},
+	})
+	assert.DeepEqual(t, s.deprecatedOperators, []RelationalOperator{LikeOperator})
+
+	_, err = Parse(`{"groups":{"op":"and","filters":[{"field":"owner_id","op":"eq","value":"$you"}]}}`, opts)
+	assert.ErrorContains(t, err, `unknown value placeholder "$you"`)
+	assert.Equal(t, StatusCode(err), http.StatusUnprocessableEntity)
+}
+func TestDecodeSearchRequestNumbers(t *testing.T) {
+	t.Parallel()
+
+	_, err := decodeSearchRequest(`{"limit":10.0}`, ParseOptions{}, nil)
+	assert.Error(t, err, "limit must be an integer")
+
+	_, err = decodeSearchRequest(`{"offset":1e2}`, ParseOptions{}, nil)
+	assert.Error(t, err, "offset must be an integer")
+
+	s, err := decodeSearchRequest(`{"groups":{"op":"and","filters":[{"field":"id","op":"eq","value":9223372036854775807}]}}`, ParseOptions{}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, s.Groups.Filters[0].Value, "9223372036854775807")
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add(`{"limit":10,"offset":0}`)
+	f.Add(`{"groups":{"op":"and","filters":[{"field":"name","op":"in","value":["a","b"]}]}}`)
+	f.Add(`{"groups":{"op":"or","groups":[{"op":"and","filters":[{"field":"data.a.b","op":"eq","value":"x"}]}]}}`)
+	f.Add(`{"order_by":[{"field":"name","direction":"desc"}],"term":"50%_off"}`)
+	f.Add(`{"groups":{"op":"and","filters":[{"field":"name","op":"gt","value_field":"name"}]}}`)
+	f.Add(strings.Repeat(`{"groups":[`, 64))
+	f.Add(`null`)
+
+	opts := NewOptions(
+		WithLogicalOperators(AndOperator, OrOperator),
+		WithAllOperators(),
+		WithFilterFields("name", "data"),
+		WithOrderFields("name"),
+		WithFieldType("data", TypeJSONB),
+		WithSearchTermFields("name"),
+		WithLimit(100),
+	)
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		s, err := Parse(raw, opts)
+		if err != nil {
+			return
+		}
+
+		if _, _, err := s.ToNamedSQL(); err != nil {
+			return
+		}
+		_, _ = s.ToElasticQuery()
+	})
+}
+
 func TestGetSearchRequest(t *testing.T) {
 	t.Parallel()
 
@@ -482,3 +3210,187 @@ func TestGetSearchRequest(t *testing.T) {
 		assert.Equal(t, s, expected)
 	})
 }
+
+func TestGetParseDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		query      string
+		expectedOK bool
+	}{
+		{
+			name:       "with timing enabled",
+			enabled:    true,
+			query:      "/?q=" + url.QueryEscape(`{"limit":5}`),
+			expectedOK: true,
+		},
+		{
+			name:       "with timing enabled and search missing",
+			enabled:    true,
+			query:      "/",
+			expectedOK: true,
+		},
+		{
+			name:    "with timing disabled",
+			enabled: false,
+			query:   "/?q=" + url.QueryEscape(`{"limit":5}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got time.Duration
+				ok  bool
+			)
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, ok = GetParseDuration(r)
+			})
+			handler := NewSearchHandler(WithQueryParam("q"), WithSearchMandatory(false), WithTimingInContext(tt.enabled))(next)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.query, nil))
+
+			assert.Equal(t, rec.Code, http.StatusOK)
+			assert.Equal(t, ok, tt.expectedOK)
+			assert.Assert(t, got >= 0)
+		})
+	}
+}
+
+func TestGetRawSearchQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		query    string
+		expected string
+	}{
+		{
+			name:     "with raw query stored",
+			enabled:  true,
+			query:    "/?q=" + url.QueryEscape(`{"limit":5}`),
+			expected: `{"limit":5}`,
+		},
+		{
+			name:     "with raw query stored and unescaped payload",
+			enabled:  true,
+			query:    `/?q={"term":"a&b"}`,
+			expected: `{"term":"a&b"}`,
+		},
+		{
+			name:     "with raw query stored and pagination params only",
+			enabled:  true,
+			query:    "/?limit=5",
+			expected: "",
+		},
+		{
+			name:     "with raw query not stored",
+			enabled:  false,
+			query:    "/?q=" + url.QueryEscape(`{"limit":5}`),
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetRawSearchQuery(r)
+			})
+			handler := NewSearchHandler(
+				WithQueryParam("q"),
+				WithSearchTermFields("name"),
+				WithSeparatePaginationParams("limit", "offset"),
+				WithStoreRawQuery(tt.enabled),
+			)(next)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.query, nil))
+
+			assert.Equal(t, rec.Code, http.StatusOK)
+			assert.Equal(t, got, tt.expected)
+		})
+	}
+}
+
+func TestGetSearchRequestWithDefaults(t *testing.T) {
+	t.Parallel()
+
+	opts := &Options{
+		limit:          ptr(100),
+		fallbackLimit:  ptr(20),
+		defaultOrderBy: []OrderClause{{Field: "id", Direction: OrderAsc}},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Assert(t, GetSearchRequestWithDefaults(req, opts) == nil)
+
+	stored := &SearchRequest{Term: ptr("alice")}
+	req = req.WithContext(context.WithValue(req.Context(), searchKey, stored))
+
+	s := GetSearchRequestWithDefaults(req, opts)
+	assert.DeepEqual(t, s, &SearchRequest{
+		OrderBy: []OrderClause{{Field: "id", Direction: OrderAsc}},
+		Limit:   ptr(20),
+		Offset:  ptr(0),
+		Term:    ptr("alice"),
+	}, cmpopts.IgnoreUnexported(SearchRequest{}))
+	assert.DeepEqual(t, stored, &SearchRequest{Term: ptr("alice")}, cmpopts.IgnoreUnexported(SearchRequest{}))
+}
+
+func TestRequireSearch(t *testing.T) {
+	t.Parallel()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		handler        http.Handler
+		path           string
+		expectedStatus int
+	}{
+		{
+			name:           "without search",
+			handler:        NewSearchHandler(WithQueryParam("q"), WithSearchMandatory(false))(RequireSearch(next)),
+			path:           "/",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "without search and custom status",
+			handler:        NewSearchHandler(WithQueryParam("q"), WithSearchMandatory(false))(RequireSearchWithStatus(http.StatusBadRequest, next)),
+			path:           "/",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "with search",
+			handler:        NewSearchHandler(WithQueryParam("q"), WithSearchMandatory(false))(RequireSearch(next)),
+			path:           "/?q=%7B%7D",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rr := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rr, req)
+			assert.Equal(t, rr.Code, tt.expectedStatus)
+		})
+	}
+}
+
+func TestWithRequiredFieldCombinations(t *testing.T) {
+	t.Parallel()
+
+	combinations := [][]string{{"tenant_id", "created_at"}}
+	opts := NewOptions(WithRequiredFieldCombinations(combinations))
+	combinations[0][0] = "name"
+
+	assert.DeepEqual(t, opts.requiredFieldCombinations, [][]string{{"tenant_id", "created_at"}})
+}
@@ -0,0 +1,44 @@
+// Package qparamstest provides helpers to test search configurations
+// built with qparams.
+package qparamstest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/paccolamano/qparams"
+)
+
+// AssertSQL parses the raw JSON search payload with opts, renders it
+// with ToNamedSQL and fails t when the condition or its arguments differ
+// from wantSQL and wantArgs. A nil wantArgs is treated as an empty map.
+//
+//	qparamstest.AssertSQL(t, `{"groups":{"op":"and","filters":[{"field":"status","op":"eq","value":"active"}]}}`,
+//		qparams.NewOptions(qparams.WithFilterFields("status")),
+//		"status = :status_0", map[string]any{"status_0": "active"})
+func AssertSQL(t testing.TB, raw string, opts *qparams.Options, wantSQL string, wantArgs map[string]any) {
+	t.Helper()
+
+	search, err := qparams.Parse(raw, opts)
+	if err != nil {
+		t.Fatalf("parse search payload: %v", err)
+		return
+	}
+
+	sql, args, err := search.ToNamedSQL()
+	if err != nil {
+		t.Fatalf("render search request: %v", err)
+		return
+	}
+
+	if sql != wantSQL {
+		t.Errorf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+
+	if wantArgs == nil {
+		wantArgs = map[string]any{}
+	}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args mismatch\n got: %v\nwant: %v", args, wantArgs)
+	}
+}
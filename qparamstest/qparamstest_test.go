@@ -0,0 +1,72 @@
+package qparamstest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/paccolamano/qparams"
+	"gotest.tools/v3/assert"
+)
+
+// recorder is a testing.TB recording failures instead of reporting them.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertSQL(t *testing.T) {
+	t.Parallel()
+
+	opts := qparams.NewOptions(qparams.WithFilterFields("status"))
+	raw := `{"groups":{"op":"and","filters":[{"field":"status","op":"eq","value":"active"}]}}`
+
+	tests := []struct {
+		name             string
+		raw              string
+		wantSQL          string
+		wantArgs         map[string]any
+		expectedFailures int
+	}{
+		{
+			name:     "with matching sql and args",
+			raw:      raw,
+			wantSQL:  "status = :status_0",
+			wantArgs: map[string]any{"status_0": "active"},
+		},
+		{
+			name:    "without filters and nil args",
+			raw:     `{}`,
+			wantSQL: "",
+		},
+		{
+			name:             "with different sql and args",
+			raw:              raw,
+			wantSQL:          "status <> :status_0",
+			wantArgs:         map[string]any{"status_0": "inactive"},
+			expectedFailures: 2,
+		},
+		{
+			name:             "with invalid payload",
+			raw:              `{"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"x"}]}}`,
+			expectedFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			AssertSQL(r, tt.raw, opts, tt.wantSQL, tt.wantArgs)
+			assert.Equal(t, len(r.failures), tt.expectedFailures, r.failures)
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package qparams
 
+import "maps"
+
 // RelationalOperator defines the set of supported comparison operators
 // that can be used in query parameters to filter results.
 type RelationalOperator string
@@ -25,11 +27,37 @@ func (o RelationalOperator) Symbol() string {
 		return "ilike"
 	case InOperator:
 		return "in"
+	case NotInOperator:
+		return "not in"
+	case EqualsNullSafeOperator:
+		return "is not distinct from"
+	case ArrayContainsOperator:
+		return "@>"
+	case IsNullOperator:
+		return "is null"
+	case IsNotNullOperator:
+		return "is not null"
+	case IEqualsOperator:
+		return "="
+	case FullTextOperator:
+		return "@@"
+	case BetweenOperator:
+		return "between"
+	case RegexOperator:
+		return "~"
+	case IRegexOperator:
+		return "~*"
 	default:
 		return "="
 	}
 }
 
+// String returns the canonical value of the RelationalOperator as sent in
+// search payloads (e.g. "eq"), unlike Symbol which returns its SQL form.
+func (o RelationalOperator) String() string {
+	return string(o)
+}
+
 const (
 	// EqualsOperator represents equality comparison (=).
 	EqualsOperator RelationalOperator = "eq"
@@ -57,6 +85,45 @@ const (
 
 	// InOperator represents an inclusion check (IN).
 	InOperator RelationalOperator = "in"
+
+	// NotInOperator represents an exclusion check (NOT IN).
+	NotInOperator RelationalOperator = "nin"
+
+	// EqualsNullSafeOperator represents a NULL-safe equality comparison
+	// (IS NOT DISTINCT FROM, <=> in MySQL), where NULL matches NULL.
+	EqualsNullSafeOperator RelationalOperator = "eqns"
+
+	// ArrayContainsOperator represents an array containment check (@>),
+	// matching array fields containing all the given values.
+	ArrayContainsOperator RelationalOperator = "array_contains"
+
+	// IsNullOperator represents a NULL check (IS NULL). The filter
+	// value is ignored.
+	IsNullOperator RelationalOperator = "isnull"
+
+	// IsNotNullOperator represents a NOT NULL check (IS NOT NULL). The
+	// filter value is ignored.
+	IsNotNullOperator RelationalOperator = "notnull"
+
+	// IEqualsOperator represents a case-insensitive equality comparison,
+	// rendered as lower(field) = lower(value).
+	IEqualsOperator RelationalOperator = "ieq"
+
+	// FullTextOperator represents a Postgres full-text match (@@) on a
+	// field configured with WithFullTextField.
+	FullTextOperator RelationalOperator = "fts"
+
+	// BetweenOperator represents an inclusive range check (BETWEEN),
+	// taking a list of exactly two values: the lower and upper bounds.
+	BetweenOperator RelationalOperator = "between"
+
+	// RegexOperator represents a case-sensitive regular expression match
+	// (~ in Postgres, REGEXP in MySQL). It is not allowed by default.
+	RegexOperator RelationalOperator = "regex"
+
+	// IRegexOperator represents a case-insensitive regular expression
+	// match (~* in Postgres). It is not allowed by default.
+	IRegexOperator RelationalOperator = "iregex"
 )
 
 var relationalOperators = map[RelationalOperator]struct{}{
@@ -69,4 +136,29 @@ var relationalOperators = map[RelationalOperator]struct{}{
 	LikeOperator:              {},
 	ILikeOperator:             {},
 	InOperator:                {},
+	NotInOperator:             {},
+	EqualsNullSafeOperator:    {},
+	ArrayContainsOperator:     {},
+	IsNullOperator:            {},
+	IsNotNullOperator:         {},
+	IEqualsOperator:           {},
+	FullTextOperator:          {},
+	BetweenOperator:           {},
+	RegexOperator:             {},
+	IRegexOperator:            {},
+}
+
+// optInOperators are the relational operators left out of the defaults,
+// as a client regex can be costly to evaluate on the database. They
+// must be allowed explicitly with WithRelationalOperators.
+var optInOperators = []RelationalOperator{RegexOperator, IRegexOperator}
+
+// withoutOperators returns a copy of ops without the given operators.
+func withoutOperators(ops map[RelationalOperator]struct{}, excluded ...RelationalOperator) map[RelationalOperator]struct{} {
+	c := maps.Clone(ops)
+	for _, op := range excluded {
+		delete(c, op)
+	}
+
+	return c
 }
@@ -59,6 +59,56 @@ func TestRelationalOperatorSymbol(t *testing.T) {
 			operator: InOperator,
 			expected: "in",
 		},
+		{
+			name:     `Symbol() should return "not in"`,
+			operator: NotInOperator,
+			expected: "not in",
+		},
+		{
+			name:     `Symbol() should return "is not distinct from"`,
+			operator: EqualsNullSafeOperator,
+			expected: "is not distinct from",
+		},
+		{
+			name:     `Symbol() should return "@>"`,
+			operator: ArrayContainsOperator,
+			expected: "@>",
+		},
+		{
+			name:     `Symbol() should return "is null"`,
+			operator: IsNullOperator,
+			expected: "is null",
+		},
+		{
+			name:     `Symbol() should return "is not null"`,
+			operator: IsNotNullOperator,
+			expected: "is not null",
+		},
+		{
+			name:     `Symbol() should return "=" for ieq`,
+			operator: IEqualsOperator,
+			expected: "=",
+		},
+		{
+			name:     `Symbol() should return "@@"`,
+			operator: FullTextOperator,
+			expected: "@@",
+		},
+		{
+			name:     `Symbol() should return "between"`,
+			operator: BetweenOperator,
+			expected: "between",
+		},
+		{
+			name:     `Symbol() should return "~"`,
+			operator: RegexOperator,
+			expected: "~",
+		},
+		{
+			name:     `Symbol() should return "~*"`,
+			operator: IRegexOperator,
+			expected: "~*",
+		},
 		{
 			name:     `Given wrong operator, Symbol() should return "="`,
 			operator: RelationalOperator("foo"),
@@ -72,3 +122,10 @@ func TestRelationalOperatorSymbol(t *testing.T) {
 		})
 	}
 }
+
+func TestRelationalOperatorString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, NotEqualsOperator.String(), "ne")
+	assert.Equal(t, RelationalOperator("foo").String(), "foo")
+}
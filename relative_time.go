@@ -0,0 +1,123 @@
+package qparams
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// relativeTimeRegexp matches the relative time placeholders, "$now" or
+// "$today" optionally followed by an offset such as "-7d" or "+1h".
+var relativeTimeRegexp = regexp.MustCompile(`^\$(now|today)(?:([+-])(\d+)([smhdw]))?$`)
+
+// relativeTimeUnits are the durations of the units of relative time
+// offsets.
+var relativeTimeUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// WithClock sets the function returning the current time used to
+// resolve the relative time placeholders, e.g. a fixed time in tests.
+// A nil clock uses time.Now.
+//
+// Relative time placeholders are "$now", or "$today" for the start of
+// the current UTC day, optionally followed by an offset in seconds (s),
+// minutes (m), hours (h), days (d) or weeks (w), e.g. "$now-7d". They
+// are resolved to an RFC 3339 UTC timestamp in the values of TypeTime
+// fields only; values of other fields are left as is.
+func WithClock(clock func() time.Time) Option {
+	return func(o *Options) {
+		o.clock = clock
+	}
+}
+
+// now returns the current time according to the clock of o.
+func (o *Options) now() time.Time {
+	if o.clock == nil {
+		return time.Now()
+	}
+
+	return o.clock()
+}
+
+// resolveRelativeTime resolves the relative time placeholder v against
+// now. It returns false when v is not a relative time placeholder.
+func resolveRelativeTime(v string, now time.Time) (string, bool, error) {
+	m := relativeTimeRegexp.FindStringSubmatch(v)
+	if m == nil {
+		return "", false, nil
+	}
+
+	now = now.UTC()
+	if m[1] == "today" {
+		now = now.Truncate(24 * time.Hour)
+	}
+
+	if m[2] != "" {
+		unit := relativeTimeUnits[m[4]]
+
+		n, err := strconv.ParseInt(m[3], 10, 64)
+		if err != nil || n > math.MaxInt64/int64(unit) {
+			return "", true, fmt.Errorf("relative time %q out of range", v)
+		}
+
+		d := time.Duration(n) * unit
+		if m[2] == "-" {
+			d = -d
+		}
+		now = now.Add(d)
+	}
+
+	return now.Format(time.RFC3339), true, nil
+}
+
+// resolveRelativeTimes replaces the relative time placeholders in the
+// values of filters on TypeTime fields. Values starting with "$now" or
+// "$today" that are not valid placeholders are rejected.
+func resolveRelativeTimes(s *SearchRequest, opts *Options) error {
+	if !slices.Contains(slices.Collect(maps.Values(opts.fieldTypes)), TypeTime) {
+		return nil
+	}
+
+	now := opts.now()
+
+	resolve := func(field, v string) (string, error) {
+		t, ok, err := resolveRelativeTime(v, now)
+		switch {
+		case err != nil:
+			return "", fmt.Errorf("field %q: %w", field, err)
+		case ok:
+			return t, nil
+		case strings.HasPrefix(v, "$now") || strings.HasPrefix(v, "$today"):
+			return "", fmt.Errorf("invalid relative time %q for field %q", v, field)
+		}
+		return v, nil
+	}
+
+	var err error
+	s.Walk(func(_ *FilterGroup, f *Filter) {
+		if f == nil || err != nil || f.ValueField != "" || opts.fieldTypes[f.Field] != TypeTime {
+			return
+		}
+
+		if f.Value, err = resolve(f.Field, f.Value); err != nil {
+			return
+		}
+		for j := range f.Values {
+			if f.Values[j], err = resolve(f.Field, f.Values[j]); err != nil {
+				return
+			}
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,185 @@
+package qparams
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestResolveRelativeTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 10, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectedOK  bool
+		expectedErr string
+	}{
+		{
+			name:       "with now",
+			value:      "$now",
+			expected:   "2024-03-10T11:30:00Z",
+			expectedOK: true,
+		},
+		{
+			name:       "with days ago",
+			value:      "$now-7d",
+			expected:   "2024-03-03T11:30:00Z",
+			expectedOK: true,
+		},
+		{
+			name:       "with hours ahead",
+			value:      "$now+2h",
+			expected:   "2024-03-10T13:30:00Z",
+			expectedOK: true,
+		},
+		{
+			name:       "with today",
+			value:      "$today",
+			expected:   "2024-03-10T00:00:00Z",
+			expectedOK: true,
+		},
+		{
+			name:       "with yesterday",
+			value:      "$today-1d",
+			expected:   "2024-03-09T00:00:00Z",
+			expectedOK: true,
+		},
+		{
+			name:  "with unknown unit",
+			value: "$now-7y",
+		},
+		{
+			name:  "with other placeholder",
+			value: "$me",
+		},
+		{
+			name:        "with offset out of range",
+			value:       "$now-99999999999w",
+			expectedOK:  true,
+			expectedErr: `relative time "$now-99999999999w" out of range`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := resolveRelativeTime(tt.value, now)
+			assert.Equal(t, ok, tt.expectedOK)
+			if tt.expectedErr != "" {
+				assert.Error(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.expected)
+		})
+	}
+}
+
+func TestResolveRelativeTimes(t *testing.T) {
+	t.Parallel()
+
+	opts := NewOptions(
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(GreaterThanEqualsOperator, InOperator),
+		WithFilterFields("created_at", "name"),
+		WithFieldType("created_at", TypeTime),
+		WithClock(func() time.Time { return time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC) }),
+	)
+
+	tests := []struct {
+		name        string
+		filter      string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "with relative time",
+			filter:   `{"field":"created_at","op":"gte","value":"$now-7d"}`,
+			expected: []string{"2024-03-03T08:00:00Z"},
+		},
+		{
+			name:     "with relative times in list",
+			filter:   `{"field":"created_at","op":"in","value":["$today","2024-01-01T00:00:00Z"]}`,
+			expected: []string{"2024-03-10T00:00:00Z", "2024-01-01T00:00:00Z"},
+		},
+		{
+			name:     "with field not of time type",
+			filter:   `{"field":"name","op":"gte","value":"$now"}`,
+			expected: []string{"$now"},
+		},
+		{
+			name:     "with dollar value on field not of time type",
+			filter:   `{"field":"name","op":"gte","value":"$5 off"}`,
+			expected: []string{"$5 off"},
+		},
+		{
+			name:        "with invalid relative time",
+			filter:      `{"field":"created_at","op":"gte","value":"$now-7y"}`,
+			expectedErr: `invalid relative time "$now-7y" for field "created_at"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(`{"groups":{"op":"and","filters":[`+tt.filter+`]}}`, opts)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				assert.Equal(t, StatusCode(err), http.StatusUnprocessableEntity)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, s.Groups.Filters[0].values(), tt.expected)
+		})
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	t.Parallel()
+
+	clock := func() time.Time { return time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC) }
+
+	var got *SearchRequest
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = GetSearchRequest(r)
+	})
+	handler := NewSearchHandler(
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(EqualsOperator, GreaterThanEqualsOperator),
+		WithFilterFields("created_at", "name"),
+		WithFieldType("created_at", TypeTime),
+		WithClock(clock),
+		WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+			http.Error(w, err.Error(), StatusCode(err))
+		}),
+	)(next)
+
+	q := url.QueryEscape(`{"groups":{"op":"and","filters":[{"field":"created_at","op":"gte","value":"$now-1w"}]}}`)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?q="+q, nil))
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, got.Groups.Filters[0].Value, "2024-03-03T00:00:00Z")
+
+	q = url.QueryEscape(`{"groups":{"op":"and","filters":[{"field":"created_at","op":"gte","value":"$now-1y"}]}}`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?q="+q, nil))
+
+	assert.Equal(t, rec.Code, http.StatusUnprocessableEntity)
+	assert.Equal(t, rec.Body.String(), `invalid relative time "$now-1y" for field "created_at"`+"\n")
+
+	q = url.QueryEscape(`{"groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"$5 off"}]}}`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?q="+q, nil))
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, got.Groups.Filters[0].Value, "$5 off")
+}
@@ -1,5 +1,14 @@
 package qparams
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
 // SearchRequest represents a structured query definition parsed from request parameters.
 // It combines filtering (via FilterGroups), ordering, and pagination options.
 //
@@ -33,8 +42,13 @@ package qparams
 type SearchRequest struct {
 	// Groups represents the root filter group, which can contain
 	// multiple filters and nested groups combined with logical operators.
+	// A null or omitted group applies no filter, like an empty group.
 	Groups *FilterGroup `json:"groups,omitempty"`
 
+	// Having is the filter group applied to the aggregates of GROUP BY
+	// queries, on the fields allowed with WithAllowedHavingFields.
+	Having *FilterGroup `json:"having,omitempty"`
+
 	// OrderBy defines the sorting rules to apply to the result set.
 	OrderBy []OrderClause `json:"order_by,omitempty"`
 
@@ -45,4 +59,293 @@ type SearchRequest struct {
 	// Offset specifies how many items to skip before starting to return results.
 	// Useful for pagination in combination with Limit.
 	Offset *int `json:"offset,omitempty"`
+
+	// Cursor is an opaque token for cursor (keyset) pagination, built by
+	// the application from the last item of the previous page. It cannot
+	// be combined with Offset.
+	Cursor *string `json:"cursor,omitempty"`
+
+	// Term is a free-text search term matched against the fields configured
+	// with WithSearchTermFields. It is expanded into an OR group of ilike
+	// filters and ANDed with Groups.
+	Term *string `json:"term,omitempty"`
+
+	// IncludeDeleted opts into soft-deleted rows, skipping the filter on
+	// the column configured with WithSoftDeleteColumn. It is rejected
+	// unless allowed with WithIncludeDeletedAllowed.
+	IncludeDeleted bool `json:"include_deleted,omitempty"`
+
+	// Distinct removes duplicate rows, e.g. produced by joins. It is
+	// rejected unless allowed with WithAllowDistinct.
+	Distinct bool `json:"distinct,omitempty"`
+
+	// options are the options s was parsed with, nil when s was not
+	// parsed by NewSearchHandler or Parse.
+	options *Options
+
+	// raw is the search payload s was decoded from, empty when it was
+	// built from the pagination query parameters only.
+	raw string
+
+	// deprecatedOperators are the deprecated relational operators sent
+	// by the client.
+	deprecatedOperators []RelationalOperator
+
+	// skippedFields are the fields not allowed whose filters and order
+	// by clauses were dropped.
+	skippedFields []string
+}
+
+// Merge combines s with other into a new SearchRequest, typically to
+// restrict a client search (other) with a server-side base search (s).
+//
+// Conflicts are resolved as follows:
+//   - root groups, and having groups, are combined under an "and" group;
+//   - order by clauses of s come first, followed by those of other on
+//     fields not already ordered;
+//   - Limit and Term of other win over those of s when set, and so do
+//     Offset and Cursor, taken together as they are mutually exclusive;
+//   - deleted rows are included and duplicates are removed when either
+//     request asks for it.
+func (s *SearchRequest) Merge(other *SearchRequest) *SearchRequest {
+	merged := s.Clone()
+	if other == nil {
+		return merged
+	}
+
+	other = other.Clone()
+
+	merged.Groups = andGroups(merged.Groups, other.Groups)
+	merged.Having = andGroups(merged.Having, other.Having)
+
+	merged.OrderBy = appendOrderClauses(merged.OrderBy, other.OrderBy...)
+
+	if other.Limit != nil {
+		merged.Limit = other.Limit
+	}
+
+	if other.Offset != nil || other.Cursor != nil {
+		merged.Offset = other.Offset
+		merged.Cursor = other.Cursor
+	}
+
+	if other.Term != nil {
+		merged.Term = other.Term
+	}
+
+	merged.IncludeDeleted = merged.IncludeDeleted || other.IncludeDeleted
+	merged.Distinct = merged.Distinct || other.Distinct
+
+	return merged
+}
+
+// Redacted returns a copy of s suitable for logging, where the values
+// of filters on the fields configured with WithRedactedFields are
+// replaced with "***". The term is redacted as well when it searches a
+// redacted field. s is left untouched.
+func (s *SearchRequest) Redacted() *SearchRequest {
+	c := s.Clone()
+	if s.options == nil || len(s.options.redactedFields) == 0 {
+		return c
+	}
+
+	redacted := s.options.redactedFields
+
+	for _, f := range s.options.searchTermFields {
+		if _, ok := redacted[f]; ok && c.Term != nil {
+			c.Term = ptr(redactedValue)
+			break
+		}
+	}
+
+	c.Walk(func(_ *FilterGroup, f *Filter) {
+		if f != nil {
+			f.redact(redacted)
+		}
+	})
+
+	return c
+}
+
+// Clone returns a deep copy of s, so that the copy can be modified
+// without affecting s.
+func (s *SearchRequest) Clone() *SearchRequest {
+	c := *s
+	c.OrderBy = slices.Clone(s.OrderBy)
+	c.Limit = clonePtr(s.Limit)
+	c.Offset = clonePtr(s.Offset)
+	c.Cursor = clonePtr(s.Cursor)
+	c.Term = clonePtr(s.Term)
+
+	if s.Groups != nil {
+		c.Groups = ptr(s.Groups.clone())
+	}
+	if s.Having != nil {
+		c.Having = ptr(s.Having.clone())
+	}
+
+	return &c
+}
+
+// Walk calls visit for the filter groups and filters of s, depth-first
+// in pre-order: the root group, then the having group, are walked by
+// calling visit with the group and a nil filter, then with the group
+// and each of its filters, then walking its nested groups in order.
+// visit can modify the groups and filters it is passed, e.g.
+//
+//	s.Walk(func(g *qparams.FilterGroup, f *qparams.Filter) {
+//	    if f != nil && f.Field == "email" {
+//	        f.Value = strings.ToLower(f.Value)
+//	    }
+//	})
+func (s *SearchRequest) Walk(visit func(*FilterGroup, *Filter)) {
+	for _, g := range []*FilterGroup{s.Groups, s.Having} {
+		if g != nil {
+			g.walk(visit)
+		}
+	}
+}
+
+// IsEmpty reports whether s has no constraint at all: no filters, no
+// having filters, no term, no order by and no pagination. Handlers can
+// use it to take an unfiltered "list all" path.
+func (s *SearchRequest) IsEmpty() bool {
+	return (s.Groups == nil || s.Groups.isEmpty()) &&
+		(s.Having == nil || s.Having.isEmpty()) &&
+		(s.Term == nil || *s.Term == "") &&
+		len(s.OrderBy) == 0 &&
+		s.Limit == nil && s.Offset == nil && s.Cursor == nil
+}
+
+// Options returns a copy of the resolved Options s was parsed with, the
+// global defaults merged with the options of the handler, or nil when s
+// was not parsed by NewSearchHandler or Parse. It is meant for debugging
+// and tests; modifying the copy does not affect the handler.
+//
+//	opts := qparams.GetSearchRequest(r).Options()
+//	limit, _ := opts.Limit()
+func (s *SearchRequest) Options() *Options {
+	if s.options == nil {
+		return nil
+	}
+
+	return s.options.clone()
+}
+
+// AlwaysFalse reports whether the root group of s obviously matches no
+// row, e.g. in with no values or eq and ne on the same value ANDed
+// together, so that callers can skip the query and return an empty
+// page. Only the contradictions of WithDetectContradictions are
+// detected: false does not mean that s matches rows.
+func (s *SearchRequest) AlwaysFalse() bool {
+	return s.Groups != nil && s.Groups.alwaysFalse()
+}
+
+// AlwaysTrue reports whether the root group of s matches every row: it
+// is missing or made of empty groups only, and there is no term. The
+// filters added by the handler, such as the soft deletion one, are part
+// of the root group once s is normalized.
+func (s *SearchRequest) AlwaysTrue() bool {
+	return (s.Groups == nil || s.Groups.alwaysTrue()) && (s.Term == nil || *s.Term == "")
+}
+
+// SkippedFields returns the fields whose filters and order by clauses
+// were dropped when parsing s with WithSkipUnknownFields, in the order
+// they were found.
+func (s *SearchRequest) SkippedFields() []string {
+	return slices.Clone(s.skippedFields)
+}
+
+// CacheKey returns a fingerprint of s, the hex SHA-256 of its JSON
+// encoding, suitable as a cache key or to correlate logs with the exact
+// query shape. Requests differing only in the order of their filters
+// have different keys.
+func (s *SearchRequest) CacheKey() string {
+	// a SearchRequest always encodes: its fields are plain values
+	data, _ := json.Marshal(s)
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// String returns a compact, human-readable form of s for logs and
+// debugging, e.g.
+//
+//	WHERE (status eq active AND role in [admin,editor]) ORDER BY created_at desc LIMIT 20 OFFSET 0
+//
+// Values are printed as is: use Redacted to hide sensitive ones first.
+func (s *SearchRequest) String() string {
+	var parts []string
+
+	if s.Distinct {
+		parts = append(parts, "DISTINCT")
+	}
+	if s.Groups != nil && !s.Groups.isEmpty() {
+		parts = append(parts, "WHERE "+s.Groups.String())
+	}
+	if s.Term != nil && *s.Term != "" {
+		parts = append(parts, "TERM "+*s.Term)
+	}
+	if s.Having != nil && !s.Having.isEmpty() {
+		parts = append(parts, "HAVING "+s.Having.String())
+	}
+
+	if len(s.OrderBy) > 0 {
+		clauses := make([]string, len(s.OrderBy))
+		for i, o := range s.OrderBy {
+			clauses[i] = o.Field + " " + o.Direction.Symbol()
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(clauses, ", "))
+	}
+
+	if s.Limit != nil {
+		parts = append(parts, "LIMIT "+strconv.Itoa(*s.Limit))
+	}
+	if s.Offset != nil {
+		parts = append(parts, "OFFSET "+strconv.Itoa(*s.Offset))
+	}
+	if s.Cursor != nil {
+		parts = append(parts, "CURSOR "+*s.Cursor)
+	}
+	if s.IncludeDeleted {
+		parts = append(parts, "INCLUDE DELETED")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// andGroups combines a and b under an "and" group, returning the other
+// one when either is nil.
+func andGroups(a, b *FilterGroup) *FilterGroup {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+
+	return &FilterGroup{
+		Op:     AndOperator,
+		Groups: []FilterGroup{*a, *b},
+	}
+}
+
+// computedFields returns the computed fields of the options s was
+// parsed with.
+func (s *SearchRequest) computedFields() map[string]string {
+	if s.options == nil {
+		return nil
+	}
+
+	return s.options.computedFields
+}
+
+// buildOptions returns the build options of the options s was parsed
+// with.
+func (s *SearchRequest) buildOptions() BuildOptions {
+	if s.options == nil {
+		return BuildOptions{}
+	}
+
+	return s.options.buildOptions
 }
@@ -0,0 +1,459 @@
+package qparams
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchRequestMerge(t *testing.T) {
+	t.Parallel()
+
+	base := FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "tenant_id", Op: EqualsOperator, Value: "1"}}}
+	client := FilterGroup{Op: OrOperator, Filters: []Filter{{Field: "name", Op: LikeOperator, Value: "a%"}}}
+
+	tests := []struct {
+		name     string
+		search   SearchRequest
+		other    *SearchRequest
+		expected *SearchRequest
+	}{
+		{
+			name:     "with nil other",
+			search:   SearchRequest{Groups: &base, Limit: ptr(10)},
+			other:    nil,
+			expected: &SearchRequest{Groups: &base, Limit: ptr(10)},
+		},
+		{
+			name:     "with groups on other only",
+			search:   SearchRequest{},
+			other:    &SearchRequest{Groups: &client},
+			expected: &SearchRequest{Groups: &client},
+		},
+		{
+			name:   "with groups on both",
+			search: SearchRequest{Groups: &base},
+			other:  &SearchRequest{Groups: &client},
+			expected: &SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Groups: []FilterGroup{base, client}},
+			},
+		},
+		{
+			name: "with order and pagination",
+			search: SearchRequest{
+				OrderBy: []OrderClause{{Field: "id", Direction: OrderAsc}},
+				Limit:   ptr(10),
+				Offset:  ptr(0),
+			},
+			other: &SearchRequest{
+				OrderBy: []OrderClause{
+					{Field: "name", Direction: OrderDesc},
+					{Field: "id", Direction: OrderDesc},
+				},
+				Limit: ptr(20),
+			},
+			expected: &SearchRequest{
+				OrderBy: []OrderClause{
+					{Field: "id", Direction: OrderAsc},
+					{Field: "name", Direction: OrderDesc},
+				},
+				Limit:  ptr(20),
+				Offset: ptr(0),
+			},
+		},
+		{
+			name:     "with cursor on other",
+			search:   SearchRequest{Offset: ptr(10)},
+			other:    &SearchRequest{Cursor: ptr("abc")},
+			expected: &SearchRequest{Cursor: ptr("abc")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.DeepEqual(t, tt.search.Merge(tt.other), tt.expected, cmpopts.IgnoreUnexported(SearchRequest{}))
+		})
+	}
+}
+
+func TestSearchRequestClone(t *testing.T) {
+	t.Parallel()
+
+	original := &SearchRequest{
+		Groups: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "role", Op: InOperator, Values: []string{"admin", "editor"}}},
+			Groups: []FilterGroup{
+				{Op: OrOperator, Filters: []Filter{{Field: "name", Op: EqualsOperator, Value: "alice"}}},
+			},
+		},
+		OrderBy: []OrderClause{{Field: "id", Direction: OrderAsc}},
+		Limit:   ptr(10),
+		Offset:  ptr(20),
+		Term:    ptr("foo"),
+	}
+	expected := &SearchRequest{
+		Groups: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "role", Op: InOperator, Values: []string{"admin", "editor"}}},
+			Groups: []FilterGroup{
+				{Op: OrOperator, Filters: []Filter{{Field: "name", Op: EqualsOperator, Value: "alice"}}},
+			},
+		},
+		OrderBy: []OrderClause{{Field: "id", Direction: OrderAsc}},
+		Limit:   ptr(10),
+		Offset:  ptr(20),
+		Term:    ptr("foo"),
+	}
+
+	clone := original.Clone()
+	assert.DeepEqual(t, clone, original, cmpopts.IgnoreUnexported(SearchRequest{}))
+
+	clone.Groups.Op = OrOperator
+	clone.Groups.Filters[0].Values[0] = "guest"
+	clone.Groups.Groups[0].Filters[0].Value = "bob"
+	clone.OrderBy[0].Direction = OrderDesc
+	*clone.Limit = 50
+	*clone.Offset = 0
+	*clone.Term = "bar"
+
+	assert.DeepEqual(t, original, expected, cmpopts.IgnoreUnexported(SearchRequest{}))
+}
+
+func TestSearchRequestOptions(t *testing.T) {
+	t.Parallel()
+
+	assert.Assert(t, (&SearchRequest{}).Options() == nil)
+
+	search, err := Parse(`{}`, NewOptions(WithFilterFields("name"), WithLimit(10)))
+	assert.NilError(t, err)
+
+	opts := search.Options()
+	assert.DeepEqual(t, opts.AllowedFilterFields(), []string{"name"})
+
+	limit, _ := opts.Limit()
+	assert.Equal(t, limit, 10)
+
+	WithFilterFields("password")(opts)
+	assert.DeepEqual(t, search.Options().AllowedFilterFields(), []string{"name"})
+}
+
+func TestSearchRequestString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		search   SearchRequest
+		expected string
+	}{
+		{
+			name:     "with zero value",
+			search:   SearchRequest{},
+			expected: "",
+		},
+		{
+			name: "with filters, order by and pagination",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{
+					{Field: "status", Op: EqualsOperator, Value: "active"},
+					{Field: "role", Op: InOperator, Values: []string{"admin", "editor"}},
+				}},
+				OrderBy: []OrderClause{{Field: "created_at", Direction: OrderDesc}},
+				Limit:   ptr(20),
+				Offset:  ptr(0),
+			},
+			expected: "WHERE (status eq active AND role in [admin,editor]) ORDER BY created_at desc LIMIT 20 OFFSET 0",
+		},
+		{
+			name: "with nested groups",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "deleted_at", Op: IsNullOperator}},
+					Groups: []FilterGroup{{Op: OrOperator, Filters: []Filter{
+						{Field: "updated_at", Op: GreaterThanOperator, ValueField: "created_at"},
+						{Field: "name", Op: LikeOperator, Value: "a%"},
+					}}},
+				},
+				Term: ptr("alice"),
+			},
+			expected: "WHERE (deleted_at isnull AND (updated_at gt field(created_at) OR name like a%)) TERM alice",
+		},
+		{
+			name: "with having and flags",
+			search: SearchRequest{
+				Having:         &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "total", Op: GreaterThanOperator, Value: "10"}}},
+				Distinct:       true,
+				IncludeDeleted: true,
+				Cursor:         ptr("abc"),
+			},
+			expected: "DISTINCT HAVING (total gt 10) CURSOR abc INCLUDE DELETED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.search.String(), tt.expected)
+		})
+	}
+}
+
+func TestSearchRequestAlwaysFalseAndTrue(t *testing.T) {
+	t.Parallel()
+
+	eq := Filter{Field: "status", Op: EqualsOperator, Value: "active"}
+	ne := Filter{Field: "status", Op: NotEqualsOperator, Value: "active"}
+	emptyIn := Filter{Field: "id", Op: InOperator, Values: []string{}}
+
+	tests := []struct {
+		name        string
+		search      SearchRequest
+		alwaysFalse bool
+		alwaysTrue  bool
+	}{
+		{
+			name:       "without groups",
+			search:     SearchRequest{},
+			alwaysTrue: true,
+		},
+		{
+			name:       "with nested empty groups",
+			search:     SearchRequest{Groups: &FilterGroup{Op: AndOperator, Groups: []FilterGroup{{Op: OrOperator}}}},
+			alwaysTrue: true,
+		},
+		{
+			name:   "with term",
+			search: SearchRequest{Term: ptr("alice")},
+		},
+		{
+			name:   "with satisfiable filters",
+			search: SearchRequest{Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{eq}}},
+		},
+		{
+			name:        "with contradictory filters",
+			search:      SearchRequest{Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{eq, ne}}},
+			alwaysFalse: true,
+		},
+		{
+			name:        "with empty in",
+			search:      SearchRequest{Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{eq, emptyIn}}},
+			alwaysFalse: true,
+		},
+		{
+			name:   "with contradictory filters in or group",
+			search: SearchRequest{Groups: &FilterGroup{Op: OrOperator, Filters: []Filter{eq, ne}}},
+		},
+		{
+			name: "with or group of unsatisfiable groups",
+			search: SearchRequest{Groups: &FilterGroup{
+				Op:      OrOperator,
+				Filters: []Filter{emptyIn},
+				Groups:  []FilterGroup{{Op: AndOperator, Filters: []Filter{eq, ne}}},
+			}},
+			alwaysFalse: true,
+		},
+		{
+			name: "with or group with an empty group",
+			search: SearchRequest{Groups: &FilterGroup{
+				Op:      OrOperator,
+				Filters: []Filter{eq},
+				Groups:  []FilterGroup{{Op: AndOperator}},
+			}},
+			alwaysTrue: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.search.AlwaysFalse(), tt.alwaysFalse)
+			assert.Equal(t, tt.search.AlwaysTrue(), tt.alwaysTrue)
+		})
+	}
+}
+
+func TestSearchRequestWalk(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+			Groups: []FilterGroup{
+				{
+					Op: OrOperator,
+					Filters: []Filter{
+						{Field: "role", Op: EqualsOperator, Value: "admin"},
+						{Field: "role", Op: EqualsOperator, Value: "editor"},
+					},
+				},
+				{Op: AndOperator},
+			},
+		},
+		Having: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "total", Op: GreaterThanOperator, Value: "10"}},
+		},
+	}
+
+	var visited []string
+	search.Walk(func(g *FilterGroup, f *Filter) {
+		if f == nil {
+			visited = append(visited, "group "+g.Op.String())
+			return
+		}
+		visited = append(visited, f.String())
+		f.Value = "x"
+	})
+
+	assert.DeepEqual(t, visited, []string{
+		"group and",
+		"status eq active",
+		"group or",
+		"role eq admin",
+		"role eq editor",
+		"group and",
+		"group and",
+		"total gt 10",
+	})
+	assert.Equal(t, search.Groups.Groups[0].Filters[1].Value, "x")
+	assert.Equal(t, search.Having.Filters[0].Value, "x")
+
+	visited = nil
+	(&SearchRequest{}).Walk(func(*FilterGroup, *Filter) { visited = append(visited, "") })
+	assert.Equal(t, len(visited), 0)
+}
+
+func TestSearchRequestIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		search   SearchRequest
+		expected bool
+	}{
+		{
+			name:     "with zero value",
+			search:   SearchRequest{},
+			expected: true,
+		},
+		{
+			name:     "with empty groups and term",
+			search:   SearchRequest{Groups: &FilterGroup{Op: AndOperator}, Term: ptr("")},
+			expected: true,
+		},
+		{
+			name: "with filters",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "id", Op: EqualsOperator, Value: "1"}}},
+			},
+			expected: false,
+		},
+		{
+			name:     "with order by",
+			search:   SearchRequest{OrderBy: []OrderClause{{Field: "id", Direction: OrderAsc}}},
+			expected: false,
+		},
+		{
+			name:     "with limit",
+			search:   SearchRequest{Limit: ptr(10)},
+			expected: false,
+		},
+		{
+			name:     "with offset",
+			search:   SearchRequest{Offset: ptr(0)},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.search.IsEmpty(), tt.expected)
+		})
+	}
+}
+
+func TestSearchRequestCacheKey(t *testing.T) {
+	t.Parallel()
+
+	newSearch := func(value string) *SearchRequest {
+		return &SearchRequest{
+			Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "name", Op: EqualsOperator, Value: value}}},
+			Limit:  ptr(10),
+		}
+	}
+
+	key := newSearch("alice").CacheKey()
+	assert.Equal(t, len(key), 64)
+	assert.Equal(t, newSearch("alice").CacheKey(), key)
+	assert.Assert(t, newSearch("bob").CacheKey() != key)
+}
+
+func TestSearchRequestRedacted(t *testing.T) {
+	t.Parallel()
+
+	raw := `{"groups":{"op":"and","filters":[` +
+		`{"field":"email","op":"in","value":["a@example.com","b@example.com"]},` +
+		`{"field":"status","op":"eq","value":"active"},` +
+		`{"field":"profile.phone","op":"eq","value":"555"}]},"term":"alice"}`
+
+	opts := NewOptions(
+		WithLogicalOperators(AndOperator, OrOperator),
+		WithRelationalOperators(EqualsOperator, InOperator, ILikeOperator),
+		WithFilterFields("email", "status", "profile"),
+		WithFieldType("profile", TypeJSONB),
+		WithSearchTermFields("name", "email"),
+		WithRedactedFields("email", "profile"),
+	)
+
+	s, err := Parse(raw, opts)
+	assert.NilError(t, err)
+
+	redacted := s.Redacted()
+
+	assert.Equal(t, *redacted.Term, "***")
+	root := redacted.Groups.Groups[0]
+	assert.DeepEqual(t, root.Filters[0].Values, []string{"***", "***"})
+	assert.Equal(t, root.Filters[1].Value, "active")
+	assert.Equal(t, root.Filters[2].Value, "***")
+	assert.DeepEqual(t, redacted.Groups.Groups[1].Filters[1].Value, "***")
+
+	assert.Equal(t, *s.Term, "alice")
+	assert.DeepEqual(t, s.Groups.Groups[0].Filters[0].Values, []string{"a@example.com", "b@example.com"})
+}
+
+func TestSearchRequestEmptyGroups(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     string
+		expected *FilterGroup
+	}{
+		{
+			name:     "with omitted groups",
+			data:     `{}`,
+			expected: nil,
+		},
+		{
+			name:     "with null groups",
+			data:     `{"groups":null}`,
+			expected: nil,
+		},
+		{
+			name:     "with empty group",
+			data:     `{"groups":{"op":"and","filters":[]}}`,
+			expected: &FilterGroup{Op: AndOperator, Filters: []Filter{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s SearchRequest
+			assert.NilError(t, json.Unmarshal([]byte(tt.data), &s))
+			assert.DeepEqual(t, s.Groups, tt.expected)
+			assert.NilError(t, validateSearchRequest(&s, NewOptions()))
+		})
+	}
+}
@@ -0,0 +1,506 @@
+package qparams
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// identifierRegexp matches the field names that can be safely
+// rendered as SQL identifiers.
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ToNamedSQL renders the root filter group of s as a SQL condition
+// using named placeholders compatible with sqlx's NamedQuery.
+//
+// Each placeholder is built from the filtered field and a counter
+// separated by an underscore (e.g. :status_0, :status_1), so the
+// same field can be filtered more than once without collisions.
+// The returned condition does not include the WHERE keyword and is
+// empty when s has no root group, and "1=1" when the root group is
+// empty.
+func (s *SearchRequest) ToNamedSQL() (string, map[string]any, error) {
+	args := map[string]any{}
+
+	var sb strings.Builder
+
+	b := &sqlBuilder{
+		sb: &sb,
+		bind: func(field, value string) string {
+			name := field + "_" + strconv.Itoa(len(args))
+			args[name] = value
+			return ":" + name
+		},
+	}
+	b.configure(s)
+
+	if err := b.writeRoot(s.Groups); err != nil {
+		return "", nil, err
+	}
+
+	return sb.String(), args, nil
+}
+
+// ToSQL renders the root filter group of s as a SQL condition for the
+// given dialect, using positional placeholders. The returned condition
+// does not include the WHERE keyword and is empty when s has no root
+// group, and "1=1" when the root group is empty.
+func (s *SearchRequest) ToSQL(dialect Dialect) (string, []any, error) {
+	var args []any
+
+	var sb strings.Builder
+
+	b := newPositionalBuilder(&sb, dialect, 0, appendArg(&args))
+	b.configure(s)
+
+	if err := b.writeRoot(s.Groups); err != nil {
+		return "", nil, err
+	}
+
+	return sb.String(), args, nil
+}
+
+// WriteSQL is like ToSQL but streams the condition to w through a
+// buffer and passes the bound values to arg in placeholder order,
+// instead of building the condition and its arguments in memory, which
+// pays off for filters carrying very large lists of values. Write
+// errors of w are returned.
+func (s *SearchRequest) WriteSQL(w io.Writer, dialect Dialect, arg func(any)) error {
+	bw := bufio.NewWriter(w)
+
+	b := newPositionalBuilder(bw, dialect, 0, arg)
+	b.configure(s)
+
+	if err := b.writeRoot(s.Groups); err != nil {
+		return err
+	}
+
+	// bufio.Writer keeps the first write error and returns it on flush
+	return bw.Flush()
+}
+
+// HavingSQL renders the having filter group of s as a SQL condition for
+// the given dialect, like ToSQL does for the root group. Placeholders
+// are numbered from 1: use HavingSQLAt to continue the numbering of
+// the WHERE condition.
+func (s *SearchRequest) HavingSQL(dialect Dialect) (string, []any, error) {
+	return s.HavingSQLAt(dialect, 0)
+}
+
+// HavingSQLAt is like HavingSQL with numbered placeholders continuing
+// from start + 1.
+func (s *SearchRequest) HavingSQLAt(dialect Dialect, start int) (string, []any, error) {
+	var args []any
+
+	var sb strings.Builder
+
+	b := newPositionalBuilder(&sb, dialect, start, appendArg(&args))
+	b.configure(s)
+
+	if err := b.writeRoot(s.Having); err != nil {
+		return "", nil, err
+	}
+
+	return sb.String(), args, nil
+}
+
+// AppendToQuery appends the WHERE, ORDER BY, LIMIT and OFFSET clauses
+// of s to the base query (e.g. "SELECT * FROM users") for the given
+// dialect, using positional placeholders. Clauses without a value in s
+// are omitted. When s is Distinct, the base query must start with
+// SELECT and DISTINCT is added after it.
+func (s *SearchRequest) AppendToQuery(base string, dialect Dialect) (string, []any, error) {
+	return s.AppendToQueryAt(base, dialect, 0)
+}
+
+// AppendToQueryAt is like AppendToQuery for a base query already using
+// start placeholders: numbered placeholders continue from start + 1.
+func (s *SearchRequest) AppendToQueryAt(base string, dialect Dialect, start int) (string, []any, error) {
+	var args []any
+
+	if s.Distinct {
+		var err error
+		if base, err = selectDistinct(base); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var sb strings.Builder
+
+	b := newPositionalBuilder(&sb, dialect, start, appendArg(&args))
+	b.configure(s)
+	b.sb.WriteString(base)
+
+	if s.Groups != nil {
+		b.sb.WriteString(" WHERE ")
+		if err := b.writeRoot(s.Groups); err != nil {
+			return "", nil, err
+		}
+	}
+
+	for i, o := range s.OrderBy {
+		if err := b.build.checkDirection(o.Direction); err != nil {
+			return "", nil, err
+		}
+
+		col, err := b.column(o.Field)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if i == 0 {
+			b.sb.WriteString(" ORDER BY ")
+		} else {
+			b.sb.WriteString(", ")
+		}
+		b.sb.WriteString(col)
+		b.sb.WriteString(" ")
+		b.sb.WriteString(o.Direction.Symbol())
+	}
+
+	if s.Limit != nil {
+		args = append(args, *s.Limit)
+		b.sb.WriteString(" LIMIT ")
+		b.sb.WriteString(dialect.placeholder(start + len(args)))
+	}
+
+	if s.Offset != nil {
+		args = append(args, *s.Offset)
+		b.sb.WriteString(" OFFSET ")
+		b.sb.WriteString(dialect.placeholder(start + len(args)))
+	}
+
+	return sb.String(), args, nil
+}
+
+// sqlBuilder renders filter groups as SQL conditions. The bind
+// function records a value of the given field and returns the
+// placeholder to render in its place. Filters on computed fields are
+// rendered with their expression template.
+type sqlBuilder struct {
+	sb        io.StringWriter
+	dialect   Dialect
+	bind      func(field, value string) string
+	computed  map[string]string
+	fullText  map[string]FullTextConfig
+	fieldMap  map[string]string
+	transform func(string) string
+	build     BuildOptions
+}
+
+// configure sets the field configurations of the options s was parsed
+// with.
+func (b *sqlBuilder) configure(s *SearchRequest) {
+	if s.options == nil {
+		return
+	}
+
+	b.computed = s.options.computedFields
+	b.fullText = s.options.fullTextFields
+	b.fieldMap = s.options.fieldMap
+	b.transform = s.options.fieldNameTransform
+	b.build = s.options.buildOptions
+}
+
+// mapColumn returns the column of the client field name col, as
+// configured with WithFieldMap and WithFieldNameTransform.
+func (b *sqlBuilder) mapColumn(col string) string {
+	if mapped, ok := b.fieldMap[col]; ok {
+		return mapped
+	}
+
+	if b.transform != nil {
+		return b.transform(col)
+	}
+
+	return col
+}
+
+// selectDistinct adds DISTINCT to the SELECT keyword starting query.
+func selectDistinct(query string) (string, error) {
+	const keyword = "SELECT "
+
+	if len(query) < len(keyword) || !strings.EqualFold(query[:len(keyword)], keyword) {
+		return "", fmt.Errorf("distinct requires a query starting with %q", keyword)
+	}
+
+	return query[:len(keyword)] + "DISTINCT " + query[len(keyword):], nil
+}
+
+// newPositionalBuilder returns a sqlBuilder writing to w, passing the
+// bound values to arg and numbering placeholders from start + 1.
+func newPositionalBuilder(w io.StringWriter, dialect Dialect, start int, arg func(any)) *sqlBuilder {
+	n := start
+
+	return &sqlBuilder{
+		sb:      w,
+		dialect: dialect,
+		bind: func(_, value string) string {
+			arg(value)
+			n++
+			return dialect.placeholder(n)
+		},
+	}
+}
+
+// appendArg returns a function appending its argument to args, for
+// newPositionalBuilder.
+func appendArg(args *[]any) func(any) {
+	return func(v any) {
+		*args = append(*args, v)
+	}
+}
+
+// writeRoot writes the root group g without surrounding parentheses.
+func (b *sqlBuilder) writeRoot(g *FilterGroup) error {
+	if g == nil {
+		return nil
+	}
+
+	return b.writeGroup(g)
+}
+
+// writeGroup writes the filters and nested groups of g joined by the
+// group logical operator. Nested groups are wrapped in parentheses
+// and empty groups are written as an always true condition.
+func (b *sqlBuilder) writeGroup(g *FilterGroup) error {
+	if g.isEmpty() {
+		b.sb.WriteString("1=1")
+		return nil
+	}
+
+	if err := b.build.checkLogical(g.Op); err != nil {
+		return err
+	}
+
+	sep := " " + g.Op.Symbol() + " "
+	first := true
+
+	for _, f := range g.Filters {
+		if !first {
+			b.sb.WriteString(sep)
+		}
+		first = false
+
+		if err := b.writeFilter(f); err != nil {
+			return err
+		}
+	}
+
+	for i := range g.Groups {
+		sg := &g.Groups[i]
+
+		if !first {
+			b.sb.WriteString(sep)
+		}
+		first = false
+
+		b.sb.WriteString("(")
+		if err := b.writeGroup(sg); err != nil {
+			return err
+		}
+		b.sb.WriteString(")")
+	}
+
+	return nil
+}
+
+// writeFilter writes a single filter condition.
+func (b *sqlBuilder) writeFilter(f Filter) error {
+	if err := b.build.checkRelational(f.Op); err != nil {
+		return err
+	}
+
+	if expr, ok := b.computed[f.Field]; ok {
+		if strings.Count(expr, "?") != 1 {
+			return fmt.Errorf("computed field %q: expression must have a single placeholder", f.Field)
+		}
+
+		b.sb.WriteString(strings.Replace(expr, "?", b.bind(f.Field, f.Value), 1))
+		return nil
+	}
+
+	if (f.Op == ArrayContainsOperator || f.Op == FullTextOperator) && b.dialect != DialectPostgres && b.dialect != "" {
+		return fmt.Errorf("relational operator %q not supported by dialect %q", f.Op, b.dialect)
+	}
+
+	col, err := b.column(f.Field)
+	if err != nil {
+		return err
+	}
+
+	if f.Op == FullTextOperator {
+		return b.writeFullText(col, f)
+	}
+
+	if f.Op == RegexOperator || f.Op == IRegexOperator {
+		if b.dialect == DialectSQLite {
+			return fmt.Errorf("relational operator %q not supported by dialect %q", f.Op, b.dialect)
+		}
+		if f.ValueField != "" {
+			return fmt.Errorf("relational operator %q does not support value_field", f.Op)
+		}
+
+		if f.Op == IRegexOperator && b.dialect == DialectMySQL {
+			b.sb.WriteString("regexp_like(" + col + ", " + b.bind(f.Field, f.Value) + ", 'i')")
+			return nil
+		}
+	}
+
+	if f.Op == IEqualsOperator {
+		if f.ValueField != "" {
+			return fmt.Errorf("relational operator %q does not support value_field", f.Op)
+		}
+
+		b.sb.WriteString("lower(" + col + ") = lower(" + b.bind(f.Field, f.Value) + ")")
+		return nil
+	}
+
+	if f.Op == InOperator && b.build.ExpandInToOr && f.ValueField == "" && len(f.values()) > 0 {
+		b.writeInAsOr(col, f)
+		return nil
+	}
+
+	b.sb.WriteString(col)
+	b.sb.WriteString(" ")
+	b.sb.WriteString(b.dialect.symbol(f.Op))
+
+	if f.isNullCheck() {
+		return nil
+	}
+
+	b.sb.WriteString(" ")
+
+	if f.ValueField != "" {
+		other, err := b.column(f.ValueField)
+		if err != nil {
+			return err
+		}
+
+		b.sb.WriteString(other)
+		return nil
+	}
+
+	switch f.Op {
+	case InOperator, NotInOperator:
+		b.sb.WriteString("(")
+		b.writeValues(f)
+		b.sb.WriteString(")")
+	case ArrayContainsOperator:
+		b.sb.WriteString("ARRAY[")
+		b.writeValues(f)
+		b.sb.WriteString("]")
+	case BetweenOperator:
+		values := f.values()
+		if len(values) != 2 {
+			return fmt.Errorf("%q filter requires exactly two values", f.Op)
+		}
+		b.sb.WriteString(b.bind(f.Field, values[0]) + " and " + b.bind(f.Field, values[1]))
+	default:
+		b.sb.WriteString(b.bind(f.Field, f.Value))
+	}
+
+	return nil
+}
+
+// writeFullText writes a Postgres full-text match of col against the
+// value of f, using the full-text configuration of its field.
+func (b *sqlBuilder) writeFullText(col string, f Filter) error {
+	if f.ValueField != "" {
+		return fmt.Errorf("relational operator %q does not support value_field", f.Op)
+	}
+
+	config := b.fullText[f.Field]
+
+	queryFunc := config.QueryFunc
+	if queryFunc == "" {
+		queryFunc = "plainto_tsquery"
+	}
+	if !slices.Contains(fullTextQueryFuncs, queryFunc) {
+		return fmt.Errorf("full-text field %q: unsupported query function %q", f.Field, queryFunc)
+	}
+
+	language := ""
+	if config.Language != "" {
+		if !identifierRegexp.MatchString(config.Language) {
+			return fmt.Errorf("full-text field %q: invalid language %q", f.Field, config.Language)
+		}
+		language = "'" + config.Language + "', "
+	}
+
+	b.sb.WriteString("to_tsvector(" + language + col + ") @@ " + queryFunc + "(" + language + b.bind(f.Field, f.Value) + ")")
+	return nil
+}
+
+// writeInAsOr writes the in filter f as equalities on col joined by
+// "or", in parentheses when there is more than one value.
+func (b *sqlBuilder) writeInAsOr(col string, f Filter) {
+	values := f.values()
+
+	if len(values) > 1 {
+		b.sb.WriteString("(")
+	}
+	for i, v := range values {
+		if i > 0 {
+			b.sb.WriteString(" or ")
+		}
+		b.sb.WriteString(col + " = " + b.bind(f.Field, v))
+	}
+	if len(values) > 1 {
+		b.sb.WriteString(")")
+	}
+}
+
+// writeValues writes the comma-separated placeholders of the values
+// of f.
+func (b *sqlBuilder) writeValues(f Filter) {
+	for i, v := range f.values() {
+		if i > 0 {
+			b.sb.WriteString(", ")
+		}
+		b.sb.WriteString(b.bind(f.Field, v))
+	}
+}
+
+// column renders the column of field. A dotted field is rendered as a
+// text extraction of the path after the first dot from the JSON column
+// named by the first segment, e.g. data.address.city becomes
+// data->'address'->>'city' (data->>'$.address.city' in MySQL and SQLite).
+func (b *sqlBuilder) column(field string) (string, error) {
+	segments := strings.Split(field, ".")
+	segments[0] = b.mapColumn(segments[0])
+
+	for _, s := range segments {
+		if !identifierRegexp.MatchString(s) {
+			return "", fmt.Errorf("invalid field name %q", field)
+		}
+	}
+
+	if len(segments) == 1 {
+		return segments[0], nil
+	}
+
+	col, path := segments[0], segments[1:]
+
+	if b.dialect == DialectMySQL || b.dialect == DialectSQLite {
+		return col + "->>'$." + strings.Join(path, ".") + "'", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(col)
+	for i, p := range path {
+		if i == len(path)-1 {
+			sb.WriteString("->>'")
+		} else {
+			sb.WriteString("->'")
+		}
+		sb.WriteString(p)
+		sb.WriteString("'")
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,624 @@
+package qparams
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchRequestToNamedSQL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		search       SearchRequest
+		expectedSQL  string
+		expectedArgs map[string]any
+		expectedErr  string
+	}{
+		{
+			name:         "without groups",
+			search:       SearchRequest{},
+			expectedSQL:  "",
+			expectedArgs: map[string]any{},
+		},
+		{
+			name: "with single filter",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+				},
+			},
+			expectedSQL:  "status = :status_0",
+			expectedArgs: map[string]any{"status_0": "active"},
+		},
+		{
+			name: "with same field filtered twice",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "age", Op: GreaterThanEqualsOperator, Value: "18"},
+						{Field: "age", Op: LowerThanOperator, Value: "65"},
+					},
+				},
+			},
+			expectedSQL:  "age >= :age_0 and age < :age_1",
+			expectedArgs: map[string]any{"age_0": "18", "age_1": "65"},
+		},
+		{
+			name: "with nested groups",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+					Groups: []FilterGroup{
+						{
+							Op: OrOperator,
+							Filters: []Filter{
+								{Field: "role", Op: EqualsOperator, Value: "admin"},
+								{Field: "role", Op: InOperator, Value: "editor"},
+							},
+						},
+						{Op: OrOperator},
+					},
+				},
+			},
+			expectedSQL:  "status = :status_0 and (role = :role_1 or role in (:role_2)) and (1=1)",
+			expectedArgs: map[string]any{"status_0": "active", "role_1": "admin", "role_2": "editor"},
+		},
+		{
+			name: "with invalid field name",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "name; drop table users", Op: EqualsOperator, Value: "x"}},
+				},
+			},
+			expectedErr: `invalid field name "name; drop table users"`,
+		},
+		{
+			name: "with value field",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "updated_at", Op: GreaterThanOperator, ValueField: "created_at"},
+						{Field: "status", Op: EqualsOperator, Value: "active"},
+					},
+				},
+			},
+			expectedSQL:  "updated_at > created_at and status = :status_0",
+			expectedArgs: map[string]any{"status_0": "active"},
+		},
+		{
+			name: "with invalid value field name",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "updated_at", Op: GreaterThanOperator, ValueField: "1=1 or x"}},
+				},
+			},
+			expectedErr: `invalid field name "1=1 or x"`,
+		},
+		{
+			name: "with list of values",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "role", Op: InOperator, Values: []string{"admin", "editor"}}},
+				},
+			},
+			expectedSQL:  "role in (:role_0, :role_1)",
+			expectedArgs: map[string]any{"role_0": "admin", "role_1": "editor"},
+		},
+		{
+			name: "with empty root group",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{}},
+			},
+			expectedSQL:  "1=1",
+			expectedArgs: map[string]any{},
+		},
+		{
+			name: "with invalid json path",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "data.x'y", Op: EqualsOperator, Value: "x"}},
+				},
+			},
+			expectedErr: `invalid field name "data.x'y"`,
+		},
+		{
+			name: "with array contains",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "tags", Op: ArrayContainsOperator, Values: []string{"go", "sql"}}},
+				},
+			},
+			expectedSQL:  "tags @> ARRAY[:tags_0, :tags_1]",
+			expectedArgs: map[string]any{"tags_0": "go", "tags_1": "sql"},
+		},
+		{
+			name: "with case-insensitive equality",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "email", Op: IEqualsOperator, Value: "Alice@Example.com"}},
+				},
+			},
+			expectedSQL:  "lower(email) = lower(:email_0)",
+			expectedArgs: map[string]any{"email_0": "Alice@Example.com"},
+		},
+		{
+			name:        "with case-insensitive equality on value field",
+			search:      SearchRequest{Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "a", Op: IEqualsOperator, ValueField: "b"}}}},
+			expectedErr: `relational operator "ieq" does not support value_field`,
+		},
+		{
+			name: "with null checks",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: AndOperator,
+					Filters: []Filter{
+						{Field: "deleted_at", Op: IsNullOperator},
+						{Field: "email", Op: IsNotNullOperator},
+					},
+				},
+			},
+			expectedSQL:  "deleted_at is null and email is not null",
+			expectedArgs: map[string]any{},
+		},
+		{
+			name: "with not in",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "id", Op: NotInOperator, Values: []string{"1", "2"}}},
+				},
+			},
+			expectedSQL:  "id not in (:id_0, :id_1)",
+			expectedArgs: map[string]any{"id_0": "1", "id_1": "2"},
+		},
+		{
+			name: "with between",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "price", Op: BetweenOperator, Values: []string{"1", "100"}}},
+				},
+			},
+			expectedSQL:  "price between :price_0 and :price_1",
+			expectedArgs: map[string]any{"price_0": "1", "price_1": "100"},
+		},
+		{
+			name:        "with between and a single value",
+			search:      SearchRequest{Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "price", Op: BetweenOperator, Value: "1"}}}},
+			expectedErr: `"between" filter requires exactly two values`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.search.ToNamedSQL()
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, sql, tt.expectedSQL)
+			assert.DeepEqual(t, args, tt.expectedArgs)
+		})
+	}
+}
+
+func TestSearchRequestToSQL(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op: AndOperator,
+			Filters: []Filter{
+				{Field: "status", Op: EqualsOperator, Value: "active"},
+				{Field: "manager_id", Op: EqualsNullSafeOperator, Value: "7"},
+				{Field: "data.address.country", Op: EqualsOperator, Value: "IT"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		dialect     Dialect
+		expectedSQL string
+	}{
+		{
+			name:        "with postgres dialect",
+			dialect:     DialectPostgres,
+			expectedSQL: "status = $1 and manager_id is not distinct from $2 and data->'address'->>'country' = $3",
+		},
+		{
+			name:        "with mysql dialect",
+			dialect:     DialectMySQL,
+			expectedSQL: "status = ? and manager_id <=> ? and data->>'$.address.country' = ?",
+		},
+		{
+			name:        "with sqlite dialect",
+			dialect:     DialectSQLite,
+			expectedSQL: "status = ? and manager_id is ? and data->>'$.address.country' = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := search.ToSQL(tt.dialect)
+			assert.NilError(t, err)
+			assert.Equal(t, sql, tt.expectedSQL)
+			assert.DeepEqual(t, args, []any{"active", "7", "IT"})
+		})
+	}
+}
+
+func TestSearchRequestWriteSQL(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op: AndOperator,
+			Filters: []Filter{
+				{Field: "status", Op: EqualsOperator, Value: "active"},
+				{Field: "id", Op: InOperator, Values: []string{"1", "2", "3"}},
+			},
+		},
+	}
+
+	var (
+		sb   strings.Builder
+		args []any
+	)
+	err := search.WriteSQL(&sb, DialectPostgres, func(v any) { args = append(args, v) })
+	assert.NilError(t, err)
+
+	sql, expectedArgs, err := search.ToSQL(DialectPostgres)
+	assert.NilError(t, err)
+	assert.Equal(t, sb.String(), sql)
+	assert.DeepEqual(t, args, expectedArgs)
+
+	err = search.WriteSQL(failingWriter{}, DialectPostgres, func(any) {})
+	assert.ErrorContains(t, err, "write failed")
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestSearchRequestToSQLArrayContains(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "tags", Op: ArrayContainsOperator, Value: "go"}},
+		},
+	}
+
+	sql, args, err := search.ToSQL(DialectPostgres)
+	assert.NilError(t, err)
+	assert.Equal(t, sql, "tags @> ARRAY[$1]")
+	assert.DeepEqual(t, args, []any{"go"})
+
+	_, _, err = search.ToSQL(DialectMySQL)
+	assert.ErrorContains(t, err, `relational operator "array_contains" not supported by dialect "mysql"`)
+}
+
+func TestSearchRequestAppendToQuery(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+		},
+		OrderBy: []OrderClause{
+			{Field: "created_at", Direction: OrderDesc},
+			{Field: "id", Direction: OrderAsc},
+		},
+		Limit:  ptr(20),
+		Offset: ptr(40),
+	}
+
+	tests := []struct {
+		name         string
+		search       SearchRequest
+		base         string
+		dialect      Dialect
+		start        int
+		expectedSQL  string
+		expectedArgs []any
+		expectedErr  string
+	}{
+		{
+			name:         "with postgres dialect",
+			search:       search,
+			base:         "SELECT * FROM users",
+			dialect:      DialectPostgres,
+			expectedSQL:  "SELECT * FROM users WHERE status = $1 ORDER BY created_at desc, id asc LIMIT $2 OFFSET $3",
+			expectedArgs: []any{"active", 20, 40},
+		},
+		{
+			name:         "with postgres dialect and start index",
+			search:       search,
+			base:         "SELECT * FROM users u JOIN teams t ON t.id = u.team_id AND t.org_id = $1",
+			dialect:      DialectPostgres,
+			start:        1,
+			expectedSQL:  "SELECT * FROM users u JOIN teams t ON t.id = u.team_id AND t.org_id = $1 WHERE status = $2 ORDER BY created_at desc, id asc LIMIT $3 OFFSET $4",
+			expectedArgs: []any{"active", 20, 40},
+		},
+		{
+			name:         "with mysql dialect",
+			search:       search,
+			base:         "SELECT * FROM users",
+			dialect:      DialectMySQL,
+			expectedSQL:  "SELECT * FROM users WHERE status = ? ORDER BY created_at desc, id asc LIMIT ? OFFSET ?",
+			expectedArgs: []any{"active", 20, 40},
+		},
+		{
+			name:        "without clauses",
+			search:      SearchRequest{},
+			base:        "SELECT * FROM users",
+			dialect:     DialectPostgres,
+			expectedSQL: "SELECT * FROM users",
+		},
+		{
+			name:         "with distinct",
+			search:       SearchRequest{Distinct: true, Limit: ptr(5)},
+			base:         "select u.* from users u join roles r on r.user_id = u.id",
+			dialect:      DialectPostgres,
+			expectedSQL:  "select DISTINCT u.* from users u join roles r on r.user_id = u.id LIMIT $1",
+			expectedArgs: []any{5},
+		},
+		{
+			name:        "with distinct and no select",
+			search:      SearchRequest{Distinct: true},
+			base:        "WITH x AS (SELECT 1) SELECT * FROM x",
+			dialect:     DialectPostgres,
+			expectedErr: `distinct requires a query starting with "SELECT "`,
+		},
+		{
+			name:        "with invalid order field",
+			search:      SearchRequest{OrderBy: []OrderClause{{Field: "id; drop table users", Direction: OrderAsc}}},
+			base:        "SELECT * FROM users",
+			dialect:     DialectPostgres,
+			expectedErr: `invalid field name "id; drop table users"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.search.AppendToQueryAt(tt.base, tt.dialect, tt.start)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.Equal(t, sql, tt.expectedSQL)
+			assert.DeepEqual(t, args, tt.expectedArgs)
+		})
+	}
+}
+
+func TestSearchRequestHavingSQL(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Having: &FilterGroup{
+			Op: AndOperator,
+			Filters: []Filter{
+				{Field: "orders_count", Op: GreaterThanEqualsOperator, Value: "3"},
+				{Field: "total", Op: LowerThanOperator, Value: "1000"},
+			},
+		},
+	}
+
+	sql, args, err := search.HavingSQLAt(DialectPostgres, 2)
+	assert.NilError(t, err)
+	assert.Equal(t, sql, "orders_count >= $3 and total < $4")
+	assert.DeepEqual(t, args, []any{"3", "1000"})
+
+	sql, args, err = (&SearchRequest{}).HavingSQL(DialectMySQL)
+	assert.NilError(t, err)
+	assert.Equal(t, sql, "")
+	assert.Equal(t, len(args), 0)
+}
+
+func TestSearchRequestComputedFieldSQL(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op: AndOperator,
+			Filters: []Filter{
+				{Field: "status", Op: EqualsOperator, Value: "active"},
+				{Field: "name_ci", Op: EqualsOperator, Value: "Alice"},
+			},
+		},
+		options: &Options{computedFields: map[string]string{"name_ci": "lower(name) = lower(?)"}},
+	}
+
+	sql, args, err := search.ToSQL(DialectPostgres)
+	assert.NilError(t, err)
+	assert.Equal(t, sql, "status = $1 and lower(name) = lower($2)")
+	assert.DeepEqual(t, args, []any{"active", "Alice"})
+
+	named, namedArgs, err := search.ToNamedSQL()
+	assert.NilError(t, err)
+	assert.Equal(t, named, "status = :status_0 and lower(name) = lower(:name_ci_1)")
+	assert.DeepEqual(t, namedArgs, map[string]any{"status_0": "active", "name_ci_1": "Alice"})
+
+	search.options.computedFields["name_ci"] = "lower(name) = ? or ? = ''"
+	_, _, err = search.ToSQL(DialectPostgres)
+	assert.ErrorContains(t, err, `computed field "name_ci": expression must have a single placeholder`)
+}
+
+func TestSearchRequestFullTextSQL(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op: AndOperator,
+			Filters: []Filter{
+				{Field: "body", Op: FullTextOperator, Value: "quick fox"},
+				{Field: "title", Op: FullTextOperator, Value: "lazy dog"},
+			},
+		},
+		options: &Options{fullTextFields: map[string]FullTextConfig{
+			"body":  {Language: "english"},
+			"title": {QueryFunc: "websearch_to_tsquery"},
+		}},
+	}
+
+	sql, args, err := search.ToSQL(DialectPostgres)
+	assert.NilError(t, err)
+	assert.Equal(t, sql, "to_tsvector('english', body) @@ plainto_tsquery('english', $1) and "+
+		"to_tsvector(title) @@ websearch_to_tsquery($2)")
+	assert.DeepEqual(t, args, []any{"quick fox", "lazy dog"})
+
+	_, _, err = search.ToSQL(DialectMySQL)
+	assert.ErrorContains(t, err, `relational operator "fts" not supported by dialect "mysql"`)
+
+	search.options.fullTextFields["body"] = FullTextConfig{Language: "english'); --"}
+	_, _, err = search.ToSQL(DialectPostgres)
+	assert.ErrorContains(t, err, `full-text field "body": invalid language`)
+
+	search.options.fullTextFields["body"] = FullTextConfig{QueryFunc: "lower"}
+	_, _, err = search.ToSQL(DialectPostgres)
+	assert.ErrorContains(t, err, `full-text field "body": unsupported query function "lower"`)
+}
+
+func TestSearchRequestRegexSQL(t *testing.T) {
+	t.Parallel()
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op: AndOperator,
+			Filters: []Filter{
+				{Field: "name", Op: RegexOperator, Value: "^al"},
+				{Field: "email", Op: IRegexOperator, Value: "@example"},
+			},
+		},
+	}
+
+	sql, args, err := search.ToSQL(DialectPostgres)
+	assert.NilError(t, err)
+	assert.Equal(t, sql, "name ~ $1 and email ~* $2")
+	assert.DeepEqual(t, args, []any{"^al", "@example"})
+
+	sql, _, err = search.ToSQL(DialectMySQL)
+	assert.NilError(t, err)
+	assert.Equal(t, sql, "name regexp ? and regexp_like(email, ?, 'i')")
+
+	_, _, err = search.ToSQL(DialectSQLite)
+	assert.ErrorContains(t, err, `relational operator "regex" not supported by dialect "sqlite"`)
+
+	search.Groups.Filters = []Filter{{Field: "name", Op: RegexOperator, ValueField: "pattern"}}
+	_, _, err = search.ToSQL(DialectPostgres)
+	assert.ErrorContains(t, err, `relational operator "regex" does not support value_field`)
+}
+
+func TestSearchRequestFieldMapSQL(t *testing.T) {
+	t.Parallel()
+
+	snakeCase := func(field string) string {
+		var sb strings.Builder
+		for _, r := range field {
+			if unicode.IsUpper(r) {
+				sb.WriteByte('_')
+				r = unicode.ToLower(r)
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String()
+	}
+
+	search := SearchRequest{
+		Groups: &FilterGroup{
+			Op: AndOperator,
+			Filters: []Filter{
+				{Field: "userName", Op: EqualsOperator, Value: "alice"},
+				{Field: "createdAt", Op: GreaterThanOperator, ValueField: "updatedAt"},
+				{Field: "ID", Op: EqualsOperator, Value: "1"},
+				{Field: "metaData.plan", Op: EqualsOperator, Value: "pro"},
+			},
+		},
+		OrderBy: []OrderClause{{Field: "createdAt", Direction: OrderDesc}},
+		options: &Options{
+			fieldMap:           map[string]string{"ID": "id", "updatedAt": "modified_at"},
+			fieldNameTransform: snakeCase,
+		},
+	}
+
+	sql, args, err := search.ToSQL(DialectPostgres)
+	assert.NilError(t, err)
+	assert.Equal(t, sql, "user_name = $1 and created_at > modified_at and id = $2 and meta_data->>'plan' = $3")
+	assert.DeepEqual(t, args, []any{"alice", "1", "pro"})
+
+	named, namedArgs, err := search.ToNamedSQL()
+	assert.NilError(t, err)
+	assert.Equal(t, named, "user_name = :userName_0 and created_at > modified_at and id = :ID_1 and meta_data->>'plan' = :metaData.plan_2")
+	assert.DeepEqual(t, namedArgs, map[string]any{"userName_0": "alice", "ID_1": "1", "metaData.plan_2": "pro"})
+
+	query, _, err := search.AppendToQuery("SELECT * FROM users", DialectPostgres)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(query, " ORDER BY created_at desc"))
+
+	search.options.fieldMap["ID"] = "id; drop table users"
+	_, _, err = search.ToSQL(DialectPostgres)
+	assert.ErrorContains(t, err, `invalid field name "ID"`)
+}
+
+func largeInSearch(n int) *SearchRequest {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+
+	return &SearchRequest{
+		Groups: &FilterGroup{
+			Op:      AndOperator,
+			Filters: []Filter{{Field: "id", Op: InOperator, Values: values}},
+		},
+	}
+}
+
+func BenchmarkToSQLLargeIn(b *testing.B) {
+	search := largeInSearch(10_000)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, _, err := search.ToSQL(DialectPostgres); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteSQLLargeIn(b *testing.B) {
+	search := largeInSearch(10_000)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := search.WriteSQL(io.Discard, DialectPostgres, func(any) {}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package qparams
+
+// SearchStats summarizes the size of a SearchRequest. It can be used
+// to estimate the cost of a query before running it.
+type SearchStats struct {
+	// FilterCount is the number of filters across all groups.
+	FilterCount int
+
+	// GroupCount is the number of filter groups, root included.
+	GroupCount int
+
+	// MaxDepth is the nesting depth of the filter tree. A request
+	// without groups has depth 0, a single root group has depth 1.
+	MaxDepth int
+
+	// OrderFieldCount is the number of order by clauses.
+	OrderFieldCount int
+}
+
+// Stats walks the filter tree of s once and returns its SearchStats.
+func (s *SearchRequest) Stats() SearchStats {
+	stats := SearchStats{OrderFieldCount: len(s.OrderBy)}
+
+	var walk func(g *FilterGroup, depth int)
+	walk = func(g *FilterGroup, depth int) {
+		stats.GroupCount++
+		stats.FilterCount += len(g.Filters)
+		stats.MaxDepth = max(stats.MaxDepth, depth)
+
+		for i := range g.Groups {
+			walk(&g.Groups[i], depth+1)
+		}
+	}
+
+	if s.Groups != nil {
+		walk(s.Groups, 1)
+	}
+
+	return stats
+}
+
+// EstimateSelectivity roughly estimates the fraction of rows matched by
+// the filters of s, from per-field selectivity hints in [0, 1] (e.g.
+// 0.01 for a filter on a unique-ish field). Filters of "and" groups
+// multiply their selectivities while those of "or" groups add up,
+// capped at 1. Fields without a hint and empty groups count as 1, and
+// the estimate is 1 when s has no filters or stats is empty.
+//
+// Callers can use it to reject broad scans, e.g. requiring more
+// filters when the estimate is above a threshold.
+func (s *SearchRequest) EstimateSelectivity(stats map[string]float64) float64 {
+	if len(stats) == 0 || s.Groups == nil {
+		return 1
+	}
+
+	var estimate func(g *FilterGroup) float64
+	estimate = func(g *FilterGroup) float64 {
+		if g.isEmpty() {
+			return 1
+		}
+
+		parts := make([]float64, 0, len(g.Filters)+len(g.Groups))
+		for _, f := range g.Filters {
+			v, ok := stats[f.Field]
+			if !ok {
+				v = 1
+			}
+			parts = append(parts, min(max(v, 0), 1))
+		}
+		for i := range g.Groups {
+			parts = append(parts, estimate(&g.Groups[i]))
+		}
+
+		if g.Op == OrOperator {
+			sum := 0.0
+			for _, p := range parts {
+				sum += p
+			}
+			return min(sum, 1)
+		}
+
+		product := 1.0
+		for _, p := range parts {
+			product *= p
+		}
+		return product
+	}
+
+	return estimate(s.Groups)
+}
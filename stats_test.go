@@ -0,0 +1,126 @@
+package qparams
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchRequestStats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		search   SearchRequest
+		expected SearchStats
+	}{
+		{
+			name:     "with empty search",
+			search:   SearchRequest{},
+			expected: SearchStats{},
+		},
+		{
+			name: "with nested groups and order",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "active"}},
+					Groups: []FilterGroup{
+						{
+							Op: OrOperator,
+							Filters: []Filter{
+								{Field: "role", Op: EqualsOperator, Value: "admin"},
+								{Field: "role", Op: EqualsOperator, Value: "editor"},
+							},
+							Groups: []FilterGroup{
+								{Op: AndOperator, Filters: []Filter{{Field: "id", Op: GreaterThanOperator, Value: "1"}}},
+							},
+						},
+						{Op: OrOperator},
+					},
+				},
+				OrderBy: []OrderClause{
+					{Field: "created_at", Direction: OrderDesc},
+					{Field: "id", Direction: OrderAsc},
+				},
+			},
+			expected: SearchStats{
+				FilterCount:     4,
+				GroupCount:      4,
+				MaxDepth:        3,
+				OrderFieldCount: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.DeepEqual(t, tt.search.Stats(), tt.expected)
+		})
+	}
+}
+
+func TestSearchRequestEstimateSelectivity(t *testing.T) {
+	t.Parallel()
+
+	stats := map[string]float64{"status": 0.5, "role": 0.1, "email": 0.001}
+
+	tests := []struct {
+		name     string
+		search   SearchRequest
+		stats    map[string]float64
+		expected float64
+	}{
+		{
+			name:     "without groups",
+			search:   SearchRequest{},
+			stats:    stats,
+			expected: 1,
+		},
+		{
+			name: "without stats",
+			search: SearchRequest{
+				Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "a"}}},
+			},
+			expected: 1,
+		},
+		{
+			name: "with and group and nested or group",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op:      AndOperator,
+					Filters: []Filter{{Field: "status", Op: EqualsOperator, Value: "a"}},
+					Groups: []FilterGroup{{
+						Op: OrOperator,
+						Filters: []Filter{
+							{Field: "role", Op: EqualsOperator, Value: "admin"},
+							{Field: "role", Op: EqualsOperator, Value: "editor"},
+						},
+					}},
+				},
+			},
+			stats:    stats,
+			expected: 0.1,
+		},
+		{
+			name: "with or group capped at 1",
+			search: SearchRequest{
+				Groups: &FilterGroup{
+					Op: OrOperator,
+					Filters: []Filter{
+						{Field: "status", Op: EqualsOperator, Value: "a"},
+						{Field: "unknown", Op: EqualsOperator, Value: "b"},
+					},
+				},
+			},
+			stats:    stats,
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.search.EstimateSelectivity(tt.stats), tt.expected)
+		})
+	}
+}
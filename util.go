@@ -1,6 +1,30 @@
 package qparams
 
+import "strings"
+
+// likeEscaper escapes the wildcard characters of like patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 // ptr is a helper that returns a pointer to v.
 func ptr[T any](v T) *T {
 	return &v
 }
+
+// clonePtr returns a pointer to a copy of the value pointed by v,
+// or nil when v is nil.
+func clonePtr[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+
+	return ptr(*v)
+}
+
+// escapeLike escapes the like wildcards contained in v so that it
+// is matched literally.
+func escapeLike(v string) string {
+	return likeEscaper.Replace(v)
+}
+
+// redactedValue replaces sensitive values in redacted search requests.
+const redactedValue = "***"
@@ -0,0 +1,83 @@
+package qparams
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// WithSearchExtractor sets the function NewValidationHandler uses to
+// retrieve the search request, e.g. decoded from the request body. It
+// defaults to the request stored with ContextWithSearchRequest. A nil
+// request means no search.
+func WithSearchExtractor(fn func(*http.Request) (*SearchRequest, error)) Option {
+	return func(o *Options) {
+		o.searchExtractor = fn
+	}
+}
+
+// ContextWithSearchRequest returns a copy of ctx carrying s, a search
+// request decoded by the application, for NewValidationHandler to
+// validate. GetSearchRequest does not return it until it is validated.
+func ContextWithSearchRequest(ctx context.Context, s *SearchRequest) context.Context {
+	return context.WithValue(ctx, pendingSearchKey, s)
+}
+
+// NewValidationHandler creates a middleware validating a search request
+// decoded elsewhere, for transports other than the query parameter read
+// by NewSearchHandler. The request is retrieved with the extractor set
+// with WithSearchExtractor, or from ContextWithSearchRequest, then
+// defaults are applied, it is validated and normalized like
+// NewSearchHandler does and the result is stored in the request
+// context. The extracted request is left untouched.
+//
+// A request already normalized, e.g. parsed by a NewSearchHandler
+// earlier in the chain, is passed on as is: validating it again would
+// check the filters injected by the first pass, such as the soft delete
+// filter, against the allowed fields.
+func NewValidationHandler(opts ...Option) func(http.Handler) http.Handler {
+	options := NewOptions(opts...)
+
+	extract := options.searchExtractor
+	if extract == nil {
+		extract = func(r *http.Request) (*SearchRequest, error) {
+			if s, ok := r.Context().Value(pendingSearchKey).(*SearchRequest); ok && s != nil {
+				return s, nil
+			}
+			return GetSearchRequest(r), nil
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			search, err := extract(r)
+			if err != nil {
+				options.errorHandler(w, r, newRequestError(http.StatusBadRequest, err))
+				return
+			}
+
+			if search == nil {
+				if options.isSearchMandatory {
+					options.errorHandler(w, r, newRequestError(http.StatusBadRequest, errors.New("missing search request")))
+					return
+				}
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// only normalized requests carry their options
+			if search.options == nil {
+				search, err = processSearchRequest(r.Context(), search.Clone(), options, requestPlaceholder(r, options))
+				if err != nil {
+					options.errorHandler(w, r, err)
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), searchKey, search)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package qparams
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestNewValidationHandler(t *testing.T) {
+	t.Parallel()
+
+	search := &SearchRequest{
+		Groups: &FilterGroup{Op: AndOperator, Filters: []Filter{{Field: "name", Op: EqualsOperator, Value: "alice"}}},
+	}
+	baseOpts := []Option{
+		WithLogicalOperators(AndOperator),
+		WithRelationalOperators(EqualsOperator),
+		WithFilterFields("name"),
+		WithLimit(10),
+		WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+			http.Error(w, err.Error(), StatusCode(err))
+		}),
+	}
+
+	tests := []struct {
+		name           string
+		opts           []Option
+		inContext      *SearchRequest
+		expectedStatus int
+		expectedLimit  *int
+	}{
+		{
+			name:           "with search in context",
+			opts:           []Option{WithSearchMandatory(true)},
+			inContext:      search,
+			expectedStatus: http.StatusOK,
+			expectedLimit:  ptr(10),
+		},
+		{
+			name: "with extractor",
+			opts: []Option{
+				WithSearchExtractor(func(*http.Request) (*SearchRequest, error) { return search, nil }),
+			},
+			expectedStatus: http.StatusOK,
+			expectedLimit:  ptr(10),
+		},
+		{
+			name: "with failing extractor",
+			opts: []Option{
+				WithSearchExtractor(func(*http.Request) (*SearchRequest, error) { return nil, errors.New("bad body") }),
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "with invalid search",
+			inContext:      &SearchRequest{Limit: ptr(50)},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "without search and mandatory",
+			opts:           []Option{WithSearchMandatory(true)},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "without search",
+			opts:           []Option{WithSearchMandatory(false)},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *SearchRequest
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetSearchRequest(r)
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := NewValidationHandler(append(baseOpts, tt.opts...)...)(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.inContext != nil {
+				req = req.WithContext(ContextWithSearchRequest(req.Context(), tt.inContext))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, rr.Code, tt.expectedStatus)
+			if tt.expectedLimit != nil {
+				assert.DeepEqual(t, got.Limit, tt.expectedLimit)
+				assert.Assert(t, search.Limit == nil)
+			}
+		})
+	}
+}
+
+func TestNewValidationHandlerAfterSearchHandler(t *testing.T) {
+	t.Parallel()
+
+	opts := []Option{
+		WithQueryParam("q"),
+		WithLogicalOperators(AndOperator, OrOperator),
+		WithRelationalOperators(EqualsOperator, ILikeOperator),
+		WithFilterFields("name"),
+		WithSearchTermFields("name"),
+		WithSoftDeleteColumn("deleted_at"),
+		WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+			http.Error(w, err.Error(), StatusCode(err))
+		}),
+	}
+
+	var parsed, got *SearchRequest
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = GetSearchRequest(r)
+		w.WriteHeader(http.StatusOK)
+	})
+	capture := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			parsed = GetSearchRequest(r)
+			h.ServeHTTP(w, r)
+		})
+	}
+	handler := NewSearchHandler(opts...)(capture(NewValidationHandler(opts...)(next)))
+
+	q := url.QueryEscape(`{"term":"alice","groups":{"op":"and","filters":[{"field":"name","op":"eq","value":"alice"}]}}`)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/?q="+q, nil))
+
+	assert.Equal(t, rr.Code, http.StatusOK, rr.Body.String())
+	assert.Equal(t, got, parsed)
+}